@@ -3,65 +3,69 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"sync"
+	"syscall"
 
 	"github.com/numbergroup/eth-monitor/pkg/alert"
 	"github.com/numbergroup/eth-monitor/pkg/config"
-	"github.com/numbergroup/eth-monitor/pkg/monitor/consensus"
-	"github.com/numbergroup/eth-monitor/pkg/monitor/execution"
+	"github.com/numbergroup/eth-monitor/pkg/heartbeat"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
 )
 
 func main() {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	// SIGTERM is sent by container runtimes and service managers, so that queued alerts are delivered on shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	confFile := flag.String("conf", "./config.yaml", "path to the configuration file")
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
 
-	var (
-		conf *config.Config
-		err  error
-	)
+	confFile := flag.String("conf", "./config.yaml", "path to the configuration file")
 	flag.Parse()
-	if confFile == nil || *confFile == "" {
-		conf, err = config.LoadConfig([]byte(os.Getenv("ETH_MONITOR_CONFIG_DATA")))
-		if err != nil {
-			panic(err)
-		}
-	} else {
-		conf, err = config.LoadConfigFromFile(*confFile)
-		if err != nil {
-			panic(err)
-		}
+
+	conf, err := loadConfig(*confFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	waitGroup := &sync.WaitGroup{}
-	conf.Log.WithField("endpoints", len(conf.Endpoints)).Info("starting monitors")
-	for _, endpoint := range conf.Endpoints {
-		alertChannels := []alert.Alert{}
-		if endpoint.Pagerduty.Enabled {
-			alertChannels = append(alertChannels, alert.NewPagerduty(conf, endpoint))
-		}
-		if endpoint.Slack.Enabled {
-			alertChannels = append(alertChannels, alert.NewSlack(conf, endpoint))
-		}
-
-		switch endpoint.Type {
-		case config.TypeExecution:
-			err := execution.RunMonitors(ctx, waitGroup, conf, endpoint, alertChannels)
-			if err != nil {
-				conf.Log.WithError(err).WithField("endpoint", endpoint.Name).Panic("failed to run monitors")
-			}
-		case config.TypeConsensus:
-			err := consensus.RunMonitors(ctx, waitGroup, conf, endpoint, alertChannels)
-			if err != nil {
-				conf.Log.WithError(err).WithField("endpoint", endpoint.Name).Panic("failed to run monitors")
-			}
-		}
+	registry := monitor.NewRegistry()
+	silences := silence.NewManager(conf)
+	deliveries := alert.NewDeliveries()
+	runHTTPServer(ctx, waitGroup, conf, registry, silences, deliveries)
 
+	conf.Log.WithField("endpoints", len(conf.Endpoints)).Info("starting monitors")
+	sup := newSupervisor(ctx, registry, silences, deliveries)
+	if err := sup.apply(conf); err != nil {
+		conf.Log.WithError(err).Panic("failed to run monitors")
+	}
+	watchConfig(ctx, waitGroup, conf, *confFile, sup)
+	if conf.Heartbeat.Enabled() {
+		beat := heartbeat.New(conf, registry)
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			beat.Run(ctx)
+		}()
 	}
 
 	waitGroup.Wait()
+	sup.wait()
 	conf.Log.Info("all monitors stopped, exiting")
 }
+
+// loadConfig loads the configuration from the file, or from the ETH_MONITOR_CONFIG_DATA
+// environment variable if no file is given.
+func loadConfig(confFile string) (*config.Config, error) {
+	data, err := readConfig(confFile)
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadConfig(data)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// configPollInterval is how often the configuration file is checked for changes.
+const configPollInterval = 10 * time.Second
+
+// watchConfig reloads the configuration on SIGHUP, and when the content of the configuration file changes.
+// The file is polled rather than watched, as mounted ConfigMaps are updated by swapping symlinks.
+// A configuration that fails to load is logged and the running configuration is kept.
+func watchConfig(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, confFile string, sup *supervisor) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	lastData, _ := readConfig(confFile)
+
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+		defer signal.Stop(hangup)
+
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+
+		log := conf.Log
+		for {
+			var forced bool
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				log.Info("received SIGHUP, reloading configuration")
+				forced = true
+			case <-ticker.C:
+				if confFile == "" {
+					continue
+				}
+			}
+
+			data, err := readConfig(confFile)
+			if err != nil {
+				log.WithError(err).WithField("file", confFile).Error("failed to read configuration")
+				continue
+			}
+			if !forced {
+				if bytes.Equal(data, lastData) {
+					continue
+				}
+				log.WithField("file", confFile).Info("configuration file changed, reloading configuration")
+			}
+			// A rejected configuration is not retried until the file changes again or on SIGHUP
+			lastData = data
+
+			newConf, err := config.LoadConfig(data)
+			if err != nil {
+				log.WithError(err).Error("failed to reload configuration, keeping the current configuration")
+				continue
+			}
+
+			log = newConf.Log
+			if err := sup.apply(newConf); err != nil {
+				log.WithError(err).Error("failed to start some monitors, retrying")
+			}
+			log.WithField("endpoints", len(newConf.Endpoints)).Info("configuration reloaded")
+		}
+	}()
+}
+
+// readConfig reads the configuration file, or the ETH_MONITOR_CONFIG_DATA environment variable if no file is given.
+func readConfig(confFile string) ([]byte, error) {
+	if confFile == "" {
+		return []byte(os.Getenv("ETH_MONITOR_CONFIG_DATA")), nil
+	}
+	return os.ReadFile(confFile)
+}
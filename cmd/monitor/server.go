@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/api"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+func runHTTPServer(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, registry *monitor.Registry, silences *silence.Manager, deliveries *alert.Deliveries) {
+	server := &http.Server{
+		Addr:              conf.ListenAddress,
+		Handler:           api.NewHandler(conf, registry, silences, deliveries),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+		conf.Log.WithField("address", conf.ListenAddress).Info("http server started")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			conf.Log.WithError(err).Error("http server exited with error")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			conf.Log.WithError(err).Error("failed to shut down http server")
+		}
+	}()
+}
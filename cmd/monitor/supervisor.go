@@ -0,0 +1,594 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/backoff"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/monitor/consensus"
+	"github.com/numbergroup/eth-monitor/pkg/monitor/execution"
+	"github.com/numbergroup/eth-monitor/pkg/monitor/generic"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
+)
+
+const (
+	startRetryMinBackoff = 5 * time.Second
+	startRetryMaxBackoff = 5 * time.Minute
+)
+
+// group is a set of monitors sharing a context, stopped and restarted together on reload.
+type group struct {
+	endpoints []config.Endpoint  // Configuration the monitors were started with
+	channels  []*alert.Channels  // Alert channels of each endpoint, replaced when the alert settings change
+	queues    *queues            // Delivery queues of the alert channels
+	cancel    context.CancelFunc // Stops the monitors
+	waitGroup *sync.WaitGroup    // Monitors of the group
+}
+
+// stop stops the monitors of the group for good, resolves the alerts they left active so that their incidents
+// are not left open, and delivers the alerts still queued.
+func (g *group) stop() {
+	g.cancel()
+	g.waitGroup.Wait()
+	for _, channels := range g.channels {
+		// Channels failing to raise the resolutions are logged by RaiseAll
+		_ = channels.ResolveAll(context.Background())
+	}
+	if g.queues != nil {
+		g.queues.stop()
+	}
+}
+
+// suspend stops the monitors of the group to start them again, handing the alerts they left active over to the
+// monitors started in their place, and delivers the alerts still queued.
+func (g *group) suspend() {
+	g.cancel()
+	g.waitGroup.Wait()
+	for _, channels := range g.channels {
+		if channels != nil {
+			channels.Handover()
+		}
+	}
+	if g.queues != nil {
+		g.queues.stop()
+	}
+}
+
+// carriedChannels are the alert channels of an endpoint whose monitors were suspended, kept until monitors are
+// started in their place or the endpoint is removed.
+type carriedChannels struct {
+	endpoint config.Endpoint // Configuration the suspended monitors were started with
+	channels *alert.Channels
+}
+
+// queues are the delivery queues of the alert channels of a group.
+type queues struct {
+	cancel    context.CancelFunc
+	waitGroup *sync.WaitGroup
+}
+
+// stop stops the queues once they have delivered the alerts still queued.
+func (q *queues) stop() {
+	q.cancel()
+	q.waitGroup.Wait()
+}
+
+// runMonitorsFunc starts the monitors of an endpoint, raising their alerts on alertChannels, until ctx is done.
+type runMonitorsFunc func(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []alert.Alert, registry *monitor.Registry) error
+
+// supervisor runs the monitors of each endpoint, and the head lag monitor of each network, in their own
+// group so that a configuration reload only restarts the monitors whose configuration changed. A change of
+// the alert settings, global or of an endpoint, replaces the alert channels of the running monitors without
+// restarting them. Restarted monitors take over the alerts left active by the monitors they replace, which
+// are only resolved once their endpoint is removed.
+type supervisor struct {
+	ctx         context.Context
+	registry    *monitor.Registry
+	silences    *silence.Manager
+	deliveries  *alert.Deliveries
+	runMonitors runMonitorsFunc
+
+	mu        sync.Mutex
+	conf      *config.Config
+	endpoints map[string]*group // Keyed by endpoint name
+	headLag   map[string]*group // Keyed by endpoint type and network
+	// Alert channels of the suspended groups, keyed by endpoint name
+	carriedEndpoints map[string]carriedChannels
+	carriedHeadLag   map[string]carriedChannels
+	retries          *backoff.Backoff // Delay before starting the monitors that failed to start again
+	retry            *time.Timer
+}
+
+func newSupervisor(ctx context.Context, registry *monitor.Registry, silences *silence.Manager, deliveries *alert.Deliveries) *supervisor {
+	return &supervisor{
+		ctx:              ctx,
+		registry:         registry,
+		silences:         silences,
+		deliveries:       deliveries,
+		runMonitors:      runMonitors,
+		endpoints:        map[string]*group{},
+		headLag:          map[string]*group{},
+		carriedEndpoints: map[string]carriedChannels{},
+		carriedHeadLag:   map[string]carriedChannels{},
+		retries:          backoff.New(startRetryMinBackoff, startRetryMaxBackoff),
+	}
+}
+
+// apply brings the running monitors in line with conf: monitors of removed or changed endpoints are stopped,
+// and monitors of added or changed endpoints are started. Endpoints that failed to start are retried with
+// backoff until they start or another configuration is applied.
+func (s *supervisor) apply(conf *config.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.applyLocked(conf)
+}
+
+func (s *supervisor) applyLocked(conf *config.Config) error {
+	if s.retry != nil {
+		s.retry.Stop()
+		s.retry = nil
+	}
+
+	restartAll := s.conf != nil && !globalsEqual(s.conf, conf)
+	replaceChannels := s.conf != nil && !restartAll && !alertSettingsEqual(s.conf, conf)
+	if restartAll {
+		conf.Log.Info("global configuration changed, restarting all monitors")
+	}
+	if s.conf != nil {
+		// Keep the logger of the running monitors, so that a verbosity change applies to them without restarting them
+		if running, ok := s.conf.Log.(*logrus.Logger); ok {
+			if reloaded, ok := conf.Log.(*logrus.Logger); ok {
+				running.SetLevel(reloaded.GetLevel())
+				conf.Log = running
+			}
+		}
+	}
+	if s.conf != nil && s.conf.ListenAddress != conf.ListenAddress {
+		conf.Log.WithField("address", s.conf.ListenAddress).Warn("listen_address changed, restart the process to apply it")
+	}
+	if s.conf != nil && s.conf.AdminToken != conf.AdminToken {
+		conf.Log.Warn("admin_token changed, restart the process to apply it")
+	}
+	if s.conf != nil && s.conf.Heartbeat != conf.Heartbeat {
+		conf.Log.Warn("heartbeat changed, restart the process to apply it")
+	}
+	// Silences apply to the running monitors as soon as they are updated, without restarting them
+	s.silences.Update(conf)
+
+	wanted := map[string]config.Endpoint{}
+	wantedHeadLag := map[string][]config.Endpoint{}
+	for _, endpoint := range conf.Endpoints {
+		wanted[endpoint.Name] = endpoint
+		if endpoint.Network != "" {
+			key := headLagKey(endpoint)
+			wantedHeadLag[key] = append(wantedHeadLag[key], endpoint)
+		}
+	}
+
+	// Stop first, so that restarted monitors do not run alongside the monitors they replace
+	for name, running := range s.endpoints {
+		endpoint, ok := wanted[name]
+		if !restartAll && ok && monitorsEqual(running.endpoints, []config.Endpoint{endpoint}) {
+			continue
+		}
+		if !ok {
+			conf.Log.WithField("endpoint", name).Info("stopping endpoint monitors")
+			running.stop()
+			metrics.DeleteEndpoint(name)
+		} else {
+			conf.Log.WithField("endpoint", name).Info("restarting endpoint monitors")
+			s.carry(running, s.carriedEndpoints)
+		}
+		delete(s.endpoints, name)
+	}
+	for key, running := range s.headLag {
+		if endpoints, ok := wantedHeadLag[key]; !restartAll && ok && monitorsEqual(running.endpoints, endpoints) {
+			continue
+		}
+		// Endpoints moved to another network are taken over by its head lag monitor, the others are resolved below
+		conf.Log.WithField("network", key).Info("stopping head lag monitor")
+		s.carry(running, s.carriedHeadLag)
+		delete(s.headLag, key)
+	}
+	s.conf = conf
+	for _, name := range s.resolveCarried(conf, s.carriedEndpoints, func(endpoint config.Endpoint) bool {
+		_, ok := wanted[endpoint.Name]
+		return ok
+	}) {
+		metrics.DeleteEndpoint(name)
+	}
+	for _, name := range s.resolveCarried(conf, s.carriedHeadLag, func(endpoint config.Endpoint) bool {
+		wantedEndpoint, ok := wanted[endpoint.Name]
+		return ok && wantedEndpoint.Network != ""
+	}) {
+		metrics.HeadLag.DeletePartialMatch(prometheus.Labels{"endpoint": name})
+	}
+
+	var errs []error
+	if replaceChannels {
+		conf.Log.Info("alert settings changed, replacing the alert channels of the running monitors")
+	}
+	// Groups whose channels cannot be replaced are stopped, and started again below
+	for name, running := range s.endpoints {
+		endpoints := []config.Endpoint{wanted[name]}
+		if !replaceChannels && reflect.DeepEqual(running.endpoints, endpoints) {
+			continue
+		}
+		running.endpoints = endpoints
+		if err := s.setAlertChannels(conf, running); err != nil {
+			conf.Log.WithError(err).WithField("endpoint", name).Error("failed to replace alert channels, restarting endpoint monitors")
+			s.carry(running, s.carriedEndpoints)
+			delete(s.endpoints, name)
+		}
+	}
+	for key, running := range s.headLag {
+		endpoints := wantedHeadLag[key]
+		if !replaceChannels && reflect.DeepEqual(running.endpoints, endpoints) {
+			continue
+		}
+		running.endpoints = endpoints
+		if err := s.setAlertChannels(conf, running); err != nil {
+			conf.Log.WithError(err).WithField("network", key).Error("failed to replace alert channels, restarting head lag monitor")
+			s.carry(running, s.carriedHeadLag)
+			delete(s.headLag, key)
+		}
+	}
+
+	for _, endpoint := range conf.Endpoints {
+		if _, ok := s.endpoints[endpoint.Name]; ok {
+			continue
+		}
+		g, err := s.startEndpoint(conf, endpoint)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to run monitors for endpoint %s", endpoint.Name))
+			continue
+		}
+		s.endpoints[endpoint.Name] = g
+	}
+	for key, endpoints := range wantedHeadLag {
+		if _, ok := s.headLag[key]; ok {
+			continue
+		}
+		g, err := s.startHeadLag(conf, endpoints)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to run head lag monitor for %s", key))
+			continue
+		}
+		s.headLag[key] = g
+	}
+
+	if len(errs) == 0 {
+		s.retries.Reset()
+		return nil
+	}
+	delay := s.retries.Next()
+	conf.Log.WithField("retry_in", delay).Info("starting the monitors that failed to start again after a delay")
+	s.retry = time.AfterFunc(delay, func() {
+		s.retryApply(conf)
+	})
+	return errors.Join(errs...)
+}
+
+// retryApply applies conf again to start the monitors that failed to start, unless another configuration
+// has been applied since or the supervisor is stopping.
+func (s *supervisor) retryApply(conf *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil || s.conf != conf {
+		return
+	}
+	if err := s.applyLocked(conf); err != nil {
+		conf.Log.WithError(err).Error("failed to start some monitors")
+	}
+}
+
+// wait blocks until all monitors have stopped, after the supervisor's context is cancelled.
+func (s *supervisor) wait() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retry != nil {
+		s.retry.Stop()
+	}
+	for _, g := range s.endpoints {
+		g.waitGroup.Wait()
+		g.queues.waitGroup.Wait()
+	}
+	for _, g := range s.headLag {
+		g.waitGroup.Wait()
+		g.queues.waitGroup.Wait()
+	}
+}
+
+func (s *supervisor) startEndpoint(conf *config.Config, endpoint config.Endpoint) (*group, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	g := &group{
+		endpoints: []config.Endpoint{endpoint},
+		channels:  takeCarried(s.carriedEndpoints, []config.Endpoint{endpoint}),
+		cancel:    cancel,
+		waitGroup: &sync.WaitGroup{},
+	}
+
+	err := s.setAlertChannels(conf, g)
+	if err == nil {
+		err = s.runMonitors(ctx, g.waitGroup, conf, endpoint, []alert.Alert{g.channels[0]}, s.registry)
+	}
+	if err != nil {
+		// Stop the monitors started before the failure, keeping their alerts for the next start
+		s.carry(g, s.carriedEndpoints)
+		return nil, err
+	}
+	resolveHandedOver(g)
+	return g, nil
+}
+
+// runMonitors starts the monitors of the endpoint for its type.
+func runMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []alert.Alert, registry *monitor.Registry) error {
+	switch endpoint.Type {
+	case config.TypeExecution:
+		return execution.RunMonitors(ctx, waitGroup, conf, endpoint, alertChannels, registry)
+	case config.TypeConsensus:
+		return consensus.RunMonitors(ctx, waitGroup, conf, endpoint, alertChannels, registry)
+	}
+	return nil
+}
+
+func (s *supervisor) startHeadLag(conf *config.Config, endpoints []config.Endpoint) (*group, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	g := &group{
+		endpoints: endpoints,
+		channels:  takeCarried(s.carriedHeadLag, endpoints),
+		cancel:    cancel,
+		waitGroup: &sync.WaitGroup{},
+	}
+	if err := s.setAlertChannels(conf, g); err != nil {
+		s.carry(g, s.carriedHeadLag)
+		return nil, err
+	}
+
+	mon := generic.NewHeadLagMonitor(conf, endpoints[0].Network, endpoints[0].Type)
+	for i, endpoint := range endpoints {
+		client, err := newHeadClient(ctx, endpoint)
+		if err != nil {
+			s.carry(g, s.carriedHeadLag)
+			return nil, errors.Wrapf(err, "failed to add endpoint %s", endpoint.Name)
+		}
+		mon.AddEndpoint(endpoint, client, []alert.Alert{g.channels[i]})
+	}
+	resolveHandedOver(g)
+
+	g.waitGroup.Add(1)
+	go func() {
+		conf.Log.WithField("name", mon.Name()).Info("head lag monitoring started")
+
+		defer g.waitGroup.Done()
+		s.registry.Register(mon)
+		defer s.registry.Unregister(mon)
+		mon.Run(ctx)
+	}()
+	return g, nil
+}
+
+// setAlertChannels creates the alert channels of every endpoint of the group from conf, delivering their alerts
+// from new queues, and replaces the channels the group's monitors raise on. The queues replaced are stopped once
+// they have delivered the alerts already queued.
+func (s *supervisor) setAlertChannels(conf *config.Config, g *group) error {
+	ctx, cancel := context.WithCancel(s.ctx)
+	q := &queues{cancel: cancel, waitGroup: &sync.WaitGroup{}}
+	alertChannels := make([][]alert.Alert, len(g.endpoints))
+	for i, endpoint := range g.endpoints {
+		var err error
+		alertChannels[i], err = s.newAlertChannels(ctx, q.waitGroup, conf, endpoint)
+		if err != nil {
+			q.stop()
+			return err
+		}
+	}
+
+	if len(g.channels) != len(g.endpoints) {
+		g.channels = make([]*alert.Channels, len(g.endpoints))
+	}
+	for i, endpointChannels := range alertChannels {
+		if g.channels[i] == nil {
+			g.channels[i] = alert.NewChannels(conf.Log, endpointChannels)
+		} else {
+			g.channels[i].Set(endpointChannels)
+		}
+	}
+	replaced := g.queues
+	g.queues = q
+	if replaced != nil {
+		replaced.stop()
+	}
+	return nil
+}
+
+// carry suspends the group, keeping the alert channels of its endpoints in carried for the monitors started in
+// their place.
+func (s *supervisor) carry(g *group, carried map[string]carriedChannels) {
+	g.suspend()
+	for i, channels := range g.channels {
+		if channels != nil {
+			carried[g.endpoints[i].Name] = carriedChannels{endpoint: g.endpoints[i], channels: channels}
+		}
+	}
+}
+
+// resolveCarried resolves the alerts handed over by the suspended monitors of the endpoints no longer wanted, on
+// alert channels created from conf for the purpose, as no monitor will take them over. It returns the names of
+// the endpoints no longer wanted.
+func (s *supervisor) resolveCarried(conf *config.Config, carried map[string]carriedChannels, wanted func(config.Endpoint) bool) []string {
+	var removed []string
+	for name, c := range carried {
+		if wanted(c.endpoint) {
+			continue
+		}
+		delete(carried, name)
+		removed = append(removed, name)
+		g := &group{
+			endpoints: []config.Endpoint{c.endpoint},
+			channels:  []*alert.Channels{c.channels},
+			cancel:    func() {},
+			waitGroup: &sync.WaitGroup{},
+		}
+		if err := s.setAlertChannels(conf, g); err != nil {
+			conf.Log.WithError(err).WithField("endpoint", name).Error("failed to create alert channels, alerts of the removed endpoint are left open")
+			continue
+		}
+		g.stop()
+	}
+	return removed
+}
+
+// takeCarried returns the alert channels carried for each of the endpoints, nil for the endpoints without, and
+// removes them from carried.
+func takeCarried(carried map[string]carriedChannels, endpoints []config.Endpoint) []*alert.Channels {
+	out := make([]*alert.Channels, len(endpoints))
+	for i, endpoint := range endpoints {
+		if c, ok := carried[endpoint.Name]; ok {
+			out[i] = c.channels
+			delete(carried, endpoint.Name)
+		}
+	}
+	return out
+}
+
+// resolveHandedOver resolves the alerts handed over to the started group that none of its monitors took over.
+func resolveHandedOver(g *group) {
+	for _, channels := range g.channels {
+		// Channels failing to raise the resolutions are logged by RaiseAll
+		_ = channels.ResolveHandedOver(context.Background())
+	}
+}
+
+// newAlertChannels returns the alert channels enabled for the endpoint, or the receivers of the routes if any are
+// configured, suppressed while the endpoint is silenced. Each channel delivers its alerts from its own queue
+// until ctx is done.
+func (s *supervisor) newAlertChannels(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint) ([]alert.Alert, error) {
+	alertChannels, err := newAlertChannels(conf, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	alertChannels = alert.WithSilences(alertChannels, s.silences, endpoint)
+	return alert.RunQueues(ctx, waitGroup, conf, endpoint, alertChannels, s.deliveries), nil
+}
+
+func newAlertChannels(conf *config.Config, endpoint config.Endpoint) ([]alert.Alert, error) {
+	if len(conf.Routes) > 0 {
+		return alert.NewRoutedChannels(conf, endpoint)
+	}
+	alertChannels := []alert.Alert{}
+	if endpoint.Pagerduty.Enabled {
+		alertChannels = append(alertChannels, alert.NewPagerduty(conf, endpoint))
+	}
+	if endpoint.Opsgenie.Enabled {
+		alertChannels = append(alertChannels, alert.NewOpsgenie(conf, endpoint))
+	}
+	if endpoint.Email.Enabled {
+		alertChannels = append(alertChannels, alert.NewEmail(conf, endpoint))
+	}
+	if endpoint.Slack.Enabled {
+		alertChannels = append(alertChannels, alert.NewSlack(conf, endpoint))
+	}
+	if endpoint.Discord.Enabled {
+		alertChannels = append(alertChannels, alert.NewDiscord(conf, endpoint))
+	}
+	if endpoint.Telegram.Enabled {
+		alertChannels = append(alertChannels, alert.NewTelegram(conf, endpoint))
+	}
+	if endpoint.Teams.Enabled {
+		alertChannels = append(alertChannels, alert.NewTeams(conf, endpoint))
+	}
+	if endpoint.Webhook.Enabled {
+		webhook, err := alert.NewWebhook(conf, endpoint)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create webhook alert channel")
+		}
+		alertChannels = append(alertChannels, webhook)
+	}
+	return alertChannels, nil
+}
+
+// newHeadClient returns the client reading the head of the endpoint for the head lag monitor.
+func newHeadClient(ctx context.Context, endpoint config.Endpoint) (generic.RPCHead, error) {
+	switch endpoint.Type {
+	case config.TypeExecution:
+		return execution.NewHeadClient(ctx, endpoint)
+	case config.TypeConsensus:
+		return consensus.NewHeadClient(endpoint), nil
+	default:
+		return nil, errors.Errorf("invalid endpoint type: %s", endpoint.Type)
+	}
+}
+
+func headLagKey(endpoint config.Endpoint) string {
+	return endpoint.Type + "::" + endpoint.Network
+}
+
+// globalsEqual returns true if the global settings used by the monitors are the same in both configurations.
+// The other global settings are applied without restarting the monitors.
+func globalsEqual(a *config.Config, b *config.Config) bool {
+	return a.RPCTimeout == b.RPCTimeout
+}
+
+// monitorsEqual returns true if the endpoints are configured the same apart from their alert settings, which are
+// applied by replacing the alert channels without restarting the monitors.
+func monitorsEqual(a []config.Endpoint, b []config.Endpoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		endpointA, endpointB := a[i], b[i]
+		clearAlertSettings(&endpointA)
+		clearAlertSettings(&endpointB)
+		if !reflect.DeepEqual(endpointA, endpointB) {
+			return false
+		}
+	}
+	return true
+}
+
+// clearAlertSettings clears the settings of the endpoint only used by its alert channels.
+func clearAlertSettings(endpoint *config.Endpoint) {
+	endpoint.Labels = nil
+	endpoint.Pagerduty = config.Pagerduty{}
+	endpoint.Opsgenie = config.Opsgenie{}
+	endpoint.Email = config.Email{}
+	endpoint.Discord = config.Discord{}
+	endpoint.Telegram = config.Telegram{}
+	endpoint.Teams = config.Teams{}
+	endpoint.Slack = config.Slack{}
+	endpoint.Webhook = config.Webhook{}
+}
+
+// alertSettingsEqual returns true if the global settings of the alert channels are the same in both configurations.
+func alertSettingsEqual(a *config.Config, b *config.Config) bool {
+	var settingsA, settingsB config.Config
+	alertSettings(&settingsA, *a)
+	alertSettings(&settingsB, *b)
+	return reflect.DeepEqual(settingsA, settingsB)
+}
+
+// alertSettings copies the global settings of the alert channels from src to dst.
+func alertSettings(dst *config.Config, src config.Config) {
+	dst.Pagerduty = src.Pagerduty
+	dst.Opsgenie = src.Opsgenie
+	dst.Email = src.Email
+	dst.Discord = src.Discord
+	dst.Telegram = src.Telegram
+	dst.Teams = src.Teams
+	dst.Slack = src.Slack
+	dst.Webhook = src.Webhook
+	dst.Receivers = src.Receivers
+	dst.Routes = src.Routes
+	dst.Delivery = src.Delivery
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/backoff"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
+)
+
+type webhookPayload struct {
+	Name   string       `json:"name"`
+	Status alert.Status `json:"status"`
+}
+
+// fakeWebhook records the alerts posted to it.
+type fakeWebhook struct {
+	mu       sync.Mutex
+	payloads []webhookPayload
+}
+
+func newFakeWebhook(t *testing.T) (*fakeWebhook, *httptest.Server) {
+	t.Helper()
+	f := &fakeWebhook{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.payloads = append(f.payloads, payload)
+		f.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeWebhook) received() []webhookPayload {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]webhookPayload(nil), f.payloads...)
+}
+
+// waitFor polls cond until it returns true, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// fakeMonitors stands in for the monitors of the endpoints, each triggering an alert once started.
+type fakeMonitors struct {
+	mu       sync.Mutex
+	starts   map[string]int
+	triggers map[string]int
+	failures map[string]int // Number of starts of each endpoint to fail
+}
+
+func newFakeMonitors() *fakeMonitors {
+	return &fakeMonitors{starts: map[string]int{}, triggers: map[string]int{}, failures: map[string]int{}}
+}
+
+func (f *fakeMonitors) run(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []alert.Alert, registry *monitor.Registry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.starts[endpoint.Name]++
+	if f.failures[endpoint.Name] > 0 {
+		f.failures[endpoint.Name]--
+		return errors.New("connection refused")
+	}
+
+	alerts := alert.NewLifecycle(conf.Log, alertChannels, endpoint.Name, "execution::FakeMonitor::"+endpoint.Name)
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+		_ = alerts.Trigger(ctx, alert.Message{Message: "down", Severity: alert.Error})
+		f.mu.Lock()
+		f.triggers[endpoint.Name]++
+		f.mu.Unlock()
+		<-ctx.Done()
+	}()
+	return nil
+}
+
+func (f *fakeMonitors) startCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.starts[name]
+}
+
+func (f *fakeMonitors) triggerCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.triggers[name]
+}
+
+func newTestSupervisor(t *testing.T, conf *config.Config) (*supervisor, *fakeMonitors) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(t.Context())
+	sup := newSupervisor(ctx, monitor.NewRegistry(), silence.NewManager(conf), alert.NewDeliveries())
+	monitors := newFakeMonitors()
+	sup.runMonitors = monitors.run
+	sup.retries = backoff.New(time.Millisecond, time.Millisecond)
+	t.Cleanup(func() {
+		cancel()
+		sup.wait()
+	})
+	return sup, monitors
+}
+
+func testSupervisorConfig(webhookURL string, names ...string) *config.Config {
+	conf := &config.Config{
+		Log:        logrus.New(),
+		RPCTimeout: time.Second,
+		Webhook:    config.Webhook{URL: webhookURL},
+	}
+	for _, name := range names {
+		conf.Endpoints = append(conf.Endpoints, config.Endpoint{
+			Name:                name,
+			Type:                config.TypeExecution,
+			URL:                 "http://" + name + ":8545",
+			NewBlockMaxDuration: time.Minute,
+			Webhook:             config.Webhook{Enabled: true},
+		})
+	}
+	return conf
+}
+
+func TestSupervisor_RestartsOnlyChangedEndpoints(t *testing.T) {
+	_, srv := newFakeWebhook(t)
+	conf := testSupervisorConfig(srv.URL, "a", "b")
+	sup, monitors := newTestSupervisor(t, conf)
+	if err := sup.apply(conf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded := testSupervisorConfig(srv.URL, "a", "b")
+	reloaded.Endpoints[1].NewBlockMaxDuration = 2 * time.Minute
+	if err := sup.apply(reloaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a, b := monitors.startCount("a"), monitors.startCount("b"); a != 1 || b != 2 {
+		t.Fatalf("expected only the changed endpoint to restart, got a=%d b=%d starts", a, b)
+	}
+
+	reloaded = testSupervisorConfig(srv.URL, "a", "b")
+	reloaded.Endpoints[1].NewBlockMaxDuration = 2 * time.Minute
+	reloaded.RPCTimeout = 2 * time.Second
+	if err := sup.apply(reloaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a, b := monitors.startCount("a"), monitors.startCount("b"); a != 2 || b != 3 {
+		t.Fatalf("expected a global change to restart every endpoint, got a=%d b=%d starts", a, b)
+	}
+}
+
+func TestSupervisor_AlertSettingsReplaceChannelsWithoutRestart(t *testing.T) {
+	before, beforeSrv := newFakeWebhook(t)
+	after, afterSrv := newFakeWebhook(t)
+	conf := testSupervisorConfig(beforeSrv.URL, "a")
+	sup, monitors := newTestSupervisor(t, conf)
+	if err := sup.apply(conf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(t, "the trigger", func() bool { return len(before.received()) == 1 })
+
+	reloaded := testSupervisorConfig(afterSrv.URL, "a")
+	reloaded.Verbosity = "debug"
+	reloaded.Log.(*logrus.Logger).SetLevel(logrus.DebugLevel)
+	if err := sup.apply(reloaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if starts := monitors.startCount("a"); starts != 1 {
+		t.Fatalf("expected the monitors to keep running, got %d starts", starts)
+	}
+	if logger := conf.Log.(*logrus.Logger); logger.GetLevel() != logrus.DebugLevel || reloaded.Log != conf.Log {
+		t.Fatal("expected the verbosity to apply to the running logger")
+	}
+
+	// The alert still active is resolved on the new channels once the endpoint is removed
+	if err := sup.apply(testSupervisorConfig(afterSrv.URL)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payloads := after.received()
+	if len(payloads) != 1 || payloads[0].Status != alert.StatusResolved || payloads[0].Name != "a" {
+		t.Fatalf("expected the resolution on the new channel, got %+v", payloads)
+	}
+	if len(before.received()) != 1 {
+		t.Fatalf("expected nothing more on the replaced channel, got %+v", before.received())
+	}
+}
+
+func TestSupervisor_StoppedEndpointResolvesAlerts(t *testing.T) {
+	webhook, srv := newFakeWebhook(t)
+	conf := testSupervisorConfig(srv.URL, "a", "b")
+	sup, _ := newTestSupervisor(t, conf)
+	if err := sup.apply(conf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(t, "the triggers", func() bool { return len(webhook.received()) == 2 })
+	metrics.BlockNumber.WithLabelValues("a").Set(1)
+	metrics.BlockNumber.WithLabelValues("b").Set(1)
+
+	if err := sup.apply(testSupervisorConfig(srv.URL, "b")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The queues of a stopped endpoint deliver the resolution before stopping
+	payloads := webhook.received()
+	if len(payloads) != 3 || payloads[2].Status != alert.StatusResolved || payloads[2].Name != "a" {
+		t.Fatalf("expected the alert of the removed endpoint to be resolved, got %+v", payloads)
+	}
+	if metrics.BlockNumber.DeleteLabelValues("a") {
+		t.Fatal("expected the metrics of the removed endpoint to be deleted")
+	}
+	if !metrics.BlockNumber.DeleteLabelValues("b") {
+		t.Fatal("expected the metrics of the remaining endpoint to be kept")
+	}
+}
+
+func TestSupervisor_RestartKeepsAlerts(t *testing.T) {
+	webhook, srv := newFakeWebhook(t)
+	conf := testSupervisorConfig(srv.URL, "a")
+	sup, monitors := newTestSupervisor(t, conf)
+	if err := sup.apply(conf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(t, "the trigger", func() bool { return len(webhook.received()) == 1 })
+
+	reloaded := testSupervisorConfig(srv.URL, "a")
+	reloaded.Endpoints[0].NewBlockMaxDuration = 2 * time.Minute
+	if err := sup.apply(reloaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(t, "the restarted monitor to trigger", func() bool { return monitors.triggerCount("a") == 2 })
+
+	if err := sup.apply(testSupervisorConfig(srv.URL)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payloads := webhook.received()
+	if len(payloads) != 2 || payloads[0].Status != alert.StatusTriggered || payloads[1].Status != alert.StatusResolved {
+		t.Fatalf("expected the alert to stay open across the restart until the endpoint is removed, got %+v", payloads)
+	}
+}
+
+func TestSupervisor_EndpointAlertSettingsReplaceChannelsWithoutRestart(t *testing.T) {
+	before, beforeSrv := newFakeWebhook(t)
+	after, afterSrv := newFakeWebhook(t)
+	conf := testSupervisorConfig(beforeSrv.URL, "a")
+	sup, monitors := newTestSupervisor(t, conf)
+	if err := sup.apply(conf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(t, "the trigger", func() bool { return len(before.received()) == 1 })
+
+	reloaded := testSupervisorConfig(beforeSrv.URL, "a")
+	reloaded.Endpoints[0].Webhook.URL = afterSrv.URL
+	reloaded.Endpoints[0].Labels = map[string]string{"team": "infra"}
+	if err := sup.apply(reloaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if starts := monitors.startCount("a"); starts != 1 {
+		t.Fatalf("expected the monitors to keep running, got %d starts", starts)
+	}
+
+	if err := sup.apply(testSupervisorConfig(beforeSrv.URL)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payloads := after.received()
+	if len(payloads) != 1 || payloads[0].Status != alert.StatusResolved {
+		t.Fatalf("expected the resolution on the endpoint's new channel, got %+v", payloads)
+	}
+	if len(before.received()) != 1 {
+		t.Fatalf("expected nothing more on the replaced channel, got %+v", before.received())
+	}
+}
+
+func TestSupervisor_RetriesFailedStart(t *testing.T) {
+	_, srv := newFakeWebhook(t)
+	conf := testSupervisorConfig(srv.URL, "a")
+	sup, monitors := newTestSupervisor(t, conf)
+	monitors.failures["a"] = 2
+
+	if err := sup.apply(conf); err == nil {
+		t.Fatal("expected the failed start to be reported")
+	}
+	waitFor(t, "the endpoint to start", func() bool { return monitors.startCount("a") == 3 })
+	waitFor(t, "the endpoint to run", func() bool {
+		sup.mu.Lock()
+		defer sup.mu.Unlock()
+		_, ok := sup.endpoints["a"]
+		return ok
+	})
+}
+
+func TestSupervisor_RetryStopsOnNewConfig(t *testing.T) {
+	_, srv := newFakeWebhook(t)
+	conf := testSupervisorConfig(srv.URL, "a")
+	sup, monitors := newTestSupervisor(t, conf)
+	sup.retries = backoff.New(time.Hour, time.Hour)
+	monitors.failures["a"] = 1
+
+	if err := sup.apply(conf); err == nil {
+		t.Fatal("expected the failed start to be reported")
+	}
+	if err := sup.apply(testSupervisorConfig(srv.URL)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	if sup.retry != nil {
+		t.Fatal("expected the retry to be cancelled once the configuration applied")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// runValidate checks the configuration without starting any monitors, and returns the exit code. The secrets
+// and files the configuration references are not read, so that it can be checked in CI.
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	confFile := flags.String("conf", "./config.yaml", "path to the configuration file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	data, err := readConfig(*confFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	conf, err := config.ValidateConfig(data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("configuration is valid, %d endpoints\n", len(conf.Endpoints))
+	return 0
+}
@@ -2,34 +2,172 @@ package alert
 
 import (
 	"context"
+	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
 )
 
 type Message struct {
 	Message  string
 	Severity Severity
 	Name     string
+	Monitor  string // Name of the monitor raising the alert, used together with Name to deduplicate
+	Status   Status
 	Metadata map[string]any
+
+	raised       time.Time // When the message was queued for delivery
+	deadLettered func()    // Called if a delivery queue gives up on the message
+}
+
+// DedupKey returns a stable key identifying the alert across repeated raises.
+func (m Message) DedupKey() string {
+	if m.Monitor == "" {
+		return m.Name
+	}
+	return m.Name + "/" + m.Monitor
+}
+
+// Resolved returns true if the message notifies that a previously triggered alert has recovered.
+func (m Message) Resolved() bool {
+	return m.Status == StatusResolved
+}
+
+// redactMessage returns msg with the secrets resolved in conf redacted from its text and metadata.
+func redactMessage(conf *config.Config, msg Message) Message {
+	msg.Message = conf.Redact(msg.Message)
+	if msg.Metadata != nil {
+		metadata := make(map[string]any, len(msg.Metadata))
+		for key, value := range msg.Metadata {
+			if s, ok := value.(string); ok {
+				value = conf.Redact(s)
+			}
+			metadata[key] = value
+		}
+		msg.Metadata = metadata
+	}
+	return msg
 }
 
 type Alert interface {
 	Raise(ctx context.Context, msg Message) error
+	Name() string
+	MinSeverity() Severity // Messages below this severity are not raised on the channel
+}
+
+// acceptor is implemented by channels that only raise some of the messages at or above their minimum severity.
+type acceptor interface {
+	Accepts(msg Message) bool
+}
+
+// digester is implemented by channels raising the messages of a window together, such as an email digest.
+// Their delivery queue collects the messages raised during the window and raises them at once when it ends.
+type digester interface {
+	DigestWindow() time.Duration // Messages are raised on their own if not positive
+	RaiseDigest(ctx context.Context, msgs []Message) error
+}
+
+// wrapper is implemented by channels adding behaviour to another channel.
+type wrapper interface {
+	unwrap() Alert
+}
+
+// findDigester returns the channel raising digests that alertChannel wraps, or nil if it does not raise digests.
+func findDigester(alertChannel Alert) digester {
+	for {
+		if d, ok := alertChannel.(digester); ok && d.DigestWindow() > 0 {
+			return d
+		}
+		w, ok := alertChannel.(wrapper)
+		if !ok {
+			return nil
+		}
+		alertChannel = w.unwrap()
+	}
 }
 
-type Severity string
+type Severity = config.Severity
+
+const (
+	Critical = config.SeverityCritical
+	Error    = config.SeverityError
+	Warning  = config.SeverityWarning
+	Info     = config.SeverityInfo
+)
+
+type Status string
 
 const (
-	Error Severity = "error"
+	StatusTriggered Status = "triggered"
+	StatusResolved  Status = "resolved"
 )
 
+// RaiseAll raises msg on every channel accepting it, and returns the errors of the channels that failed to raise it.
+// Queued channels only fail if their queue cannot take the message, delivery failures are handled by the queue.
 func RaiseAll(ctx context.Context, logger logrus.Ext1FieldLogger, alertChannels []Alert, msg Message) error {
-	var err error
-	for _, alertChannel := range alertChannels {
-		alertErr := alertChannel.Raise(ctx, msg)
-		if alertErr != nil {
-			logger.WithError(alertErr).Error("failed to raise alert")
+	_, _, err := raise(ctx, logger, alertChannels, msg)
+	return err
+}
+
+// raise raises msg on every channel accepting it. It returns whether a silence suppressed it on any of them,
+// the names of the channels that raised it, and the errors of the channels that failed to raise it.
+func raise(ctx context.Context, logger logrus.Ext1FieldLogger, alertChannels []Alert, msg Message) (silenced bool, raised []string, err error) {
+	var errs []error
+	for _, alertChannel := range expandChannels(alertChannels) {
+		if !msg.Severity.AtLeast(alertChannel.MinSeverity()) {
+			continue
+		}
+		if a, ok := alertChannel.(acceptor); ok && !a.Accepts(msg) {
+			continue
+		}
+		if s, ok := alertChannel.(silenceable); ok {
+			if silences := s.Silences(msg); len(silences) > 0 {
+				silenced = true
+				metrics.AlertsSilenced.WithLabelValues(alertChannel.Name()).Inc()
+				logger.WithFields(logrus.Fields{
+					"channel":  alertChannel.Name(),
+					"silences": silences,
+				}).Debug("alert silenced, not raising")
+				continue
+			}
+		}
+		if err := raiseOn(ctx, logger, alertChannel, msg); err != nil {
+			errs = append(errs, err)
+			continue
 		}
+		raised = append(raised, alertChannel.Name())
 	}
-	return err
+	return silenced, raised, errors.Join(errs...)
+}
+
+// raiseNamed raises msg on the channels named in names, whatever their severity and routes, so that a resolution
+// reaches exactly the channels that raised the alert it resolves.
+func raiseNamed(ctx context.Context, logger logrus.Ext1FieldLogger, alertChannels []Alert, msg Message, names map[string]struct{}) error {
+	var errs []error
+	for _, alertChannel := range expandChannels(alertChannels) {
+		if _, ok := names[alertChannel.Name()]; !ok {
+			continue
+		}
+		if err := raiseOn(ctx, logger, alertChannel, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// raiseOn raises msg on a single channel, counting and logging the result.
+func raiseOn(ctx context.Context, logger logrus.Ext1FieldLogger, alertChannel Alert, msg Message) error {
+	if err := alertChannel.Raise(ctx, msg); err != nil {
+		metrics.AlertErrors.WithLabelValues(alertChannel.Name()).Inc()
+		logger.WithError(err).WithField("channel", alertChannel.Name()).Error("failed to raise alert")
+		return errors.Wrapf(err, "failed to raise alert on %s", alertChannel.Name())
+	}
+	// Queues count their messages once delivered
+	if _, ok := alertChannel.(*Queue); !ok {
+		metrics.AlertsRaised.WithLabelValues(alertChannel.Name(), string(msg.Status)).Inc()
+	}
+	return nil
 }
@@ -0,0 +1,135 @@
+package alert
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Channels is the set of alert channels of an endpoint, raised on by the lifecycles of its monitors. A configuration
+// reload replaces the channels with Set without restarting the monitors, so that their alerts and state are kept.
+// Monitors restarted on the same set take over the alerts left active by the monitors they replace, see Handover.
+type Channels struct {
+	log logrus.Ext1FieldLogger
+
+	mu            sync.RWMutex
+	alertChannels []Alert
+	lifecycles    map[string]*Lifecycle // Lifecycles of the running monitors, keyed by dedup key
+	handedOver    map[string]*Lifecycle // Lifecycles of stopped monitors, taken over by the lifecycles with the same key
+}
+
+func NewChannels(logger logrus.Ext1FieldLogger, alertChannels []Alert) *Channels {
+	return &Channels{
+		log:           logger,
+		alertChannels: alertChannels,
+		lifecycles:    map[string]*Lifecycle{},
+		handedOver:    map[string]*Lifecycle{},
+	}
+}
+
+// Set replaces the channels that alerts are raised on.
+func (c *Channels) Set(alertChannels []Alert) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alertChannels = alertChannels
+}
+
+// Get returns the channels that alerts are raised on.
+func (c *Channels) Get() []Alert {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.alertChannels
+}
+
+// Raise raises msg on the channels of the set, like RaiseAll.
+func (c *Channels) Raise(ctx context.Context, msg Message) error {
+	return RaiseAll(ctx, c.log, c.Get(), msg)
+}
+
+func (c *Channels) Name() string {
+	return "channels"
+}
+
+func (c *Channels) MinSeverity() Severity {
+	return ""
+}
+
+// ResolveAll resolves the active alerts of the lifecycles raising on the set, including the ones handed over, once
+// their monitors have stopped for good, so that the incidents they opened are not left open. It must not be called
+// while the monitors run.
+func (c *Channels) ResolveAll(ctx context.Context) error {
+	c.mu.Lock()
+	lifecycles := c.lifecycles
+	for key, l := range c.handedOver {
+		lifecycles[key] = l
+	}
+	c.lifecycles, c.handedOver = map[string]*Lifecycle{}, map[string]*Lifecycle{}
+	c.mu.Unlock()
+	return resolveAll(ctx, lifecycles)
+}
+
+// Handover hands the lifecycles raising on the set over to the lifecycles created on it by the monitors restarted
+// in their place, so that a restart neither resolves nor raises again the alerts still active. It must be called
+// once the monitors have stopped, and before the restarted monitors are created.
+func (c *Channels) Handover() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, l := range c.lifecycles {
+		c.handedOver[key] = l
+	}
+	c.lifecycles = map[string]*Lifecycle{}
+}
+
+// ResolveHandedOver resolves the active alerts of the lifecycles handed over that no restarted monitor took over,
+// as their monitors are no longer configured.
+func (c *Channels) ResolveHandedOver(ctx context.Context) error {
+	c.mu.Lock()
+	lifecycles := c.handedOver
+	c.handedOver = map[string]*Lifecycle{}
+	c.mu.Unlock()
+	return resolveAll(ctx, lifecycles)
+}
+
+// track adds the lifecycle to the set, taking over the state of the lifecycle with the same key handed over.
+func (c *Channels) track(l *Lifecycle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := l.endpoint + "/" + l.monitor
+	if previous, ok := c.handedOver[key]; ok {
+		l.takeOver(previous)
+		delete(c.handedOver, key)
+	}
+	c.lifecycles[key] = l
+}
+
+func resolveAll(ctx context.Context, lifecycles map[string]*Lifecycle) error {
+	var errs []error
+	for _, l := range lifecycles {
+		errs = append(errs, l.Resolve(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// expandChannels returns alertChannels with every set replaced by its current channels.
+func expandChannels(alertChannels []Alert) []Alert {
+	var out []Alert
+	for i, alertChannel := range alertChannels {
+		c, ok := alertChannel.(*Channels)
+		if !ok {
+			if out != nil {
+				out = append(out, alertChannel)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]Alert, 0, len(alertChannels)), alertChannels[:i]...)
+		}
+		out = append(out, c.Get()...)
+	}
+	if out == nil {
+		return alertChannels
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package alert
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// deadLetter is a message that could not be delivered, as written to the dead-letter file.
+type deadLetter struct {
+	Time     time.Time         `json:"time"`
+	Channel  string            `json:"channel"`
+	Endpoint string            `json:"endpoint"`
+	Monitor  string            `json:"monitor,omitempty"`
+	Status   Status            `json:"status"`
+	Severity Severity          `json:"severity"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Attempts int               `json:"attempts"` // 0 if the message was never attempted, as its queue was full or stopped
+	Error    string            `json:"error"`
+}
+
+// deadLetterMu serializes the writes of the queues sharing the dead-letter file.
+var deadLetterMu sync.Mutex
+
+func newDeadLetter(conf *config.Config, channel string, msg Message, attempts int, err error) deadLetter {
+	msg = redactMessage(conf, msg)
+	var metadata map[string]string
+	if fields := metadataFields(msg); len(fields) > 0 {
+		metadata = make(map[string]string, len(fields))
+		for _, field := range fields {
+			metadata[field.Key] = field.Value
+		}
+	}
+	return deadLetter{
+		Time:     time.Now().UTC(),
+		Channel:  channel,
+		Endpoint: msg.Name,
+		Monitor:  msg.Monitor,
+		Status:   msg.Status,
+		Severity: msg.Severity.OrDefault(),
+		Message:  msg.Message,
+		Metadata: metadata,
+		Attempts: attempts,
+		Error:    conf.Redact(err.Error()),
+	}
+}
+
+// appendDeadLetter appends entry to file as a single JSON line.
+func appendDeadLetter(file string, entry deadLetter) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode dead letter")
+	}
+
+	deadLetterMu.Lock()
+	defer deadLetterMu.Unlock()
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open dead-letter file")
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to write dead-letter file")
+	}
+	return errors.Wrap(f.Close(), "failed to close dead-letter file")
+}
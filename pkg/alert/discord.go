@@ -0,0 +1,110 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const (
+	// Discord rejects embeds with longer descriptions or more fields
+	maxDiscordDescriptionLength = 4096
+	maxDiscordFields            = 25
+)
+
+// NewDiscord returns a Discord channel using the endpoint's configuration, with unset fields taken from the global configuration.
+func NewDiscord(conf *config.Config, endpoint config.Endpoint) Discord {
+	return newDiscord(conf, "discord", endpoint.Discord.Or(conf.Discord))
+}
+
+func newDiscord(conf *config.Config, name string, discord config.Discord) Discord {
+	return Discord{
+		client:      &http.Client{Timeout: conf.RPCTimeout},
+		webhookURL:  discord.WebhookURL,
+		name:        name,
+		minSeverity: discord.MinSeverity,
+		conf:        conf,
+	}
+}
+
+// Discord posts alerts as embeds through a channel webhook.
+type Discord struct {
+	client     *http.Client
+	webhookURL string
+
+	name        string
+	minSeverity Severity
+	conf        *config.Config
+}
+
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func (d Discord) Name() string {
+	return d.name
+}
+
+func (d Discord) MinSeverity() Severity {
+	return d.minSeverity
+}
+
+func (d Discord) Raise(ctx context.Context, msg Message) error {
+	msg = redactMessage(d.conf, msg)
+	return postJSON(ctx, d.client, "discord", d.webhookURL, nil, discordMessage{
+		Embeds: []discordEmbed{{
+			Title:       truncate(summary(msg), 256),
+			Description: truncate(msg.Message, maxDiscordDescriptionLength),
+			Color:       d.messageColor(msg),
+			Fields:      d.buildMetadataFields(msg),
+			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		}},
+	})
+}
+
+func (d Discord) messageColor(msg Message) int {
+	if msg.Resolved() {
+		return 0x2eb67d
+	}
+	return d.severityColor(msg.Severity)
+}
+
+func (d Discord) severityColor(severity Severity) int {
+	switch severity.OrDefault() {
+	case Critical:
+		return 0x992d22
+	case Error:
+		return 0xe01e5a
+	case Info:
+		return 0x439fe0
+	default:
+		return 0xecb22e
+	}
+}
+
+func (d Discord) buildMetadataFields(msg Message) []discordField {
+	var fields []discordField
+	for _, field := range metadataFields(msg) {
+		if len(fields) == maxDiscordFields {
+			break
+		}
+		fields = append(fields, discordField{Name: field.Key, Value: truncate(field.Value, 1024), Inline: true})
+	}
+	return fields
+}
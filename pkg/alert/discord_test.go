@@ -0,0 +1,46 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func TestDiscord_Embed(t *testing.T) {
+	srv, reqs := newWebhookServer(t, http.StatusNoContent)
+	conf := &config.Config{RPCTimeout: time.Second, Discord: config.Discord{WebhookURL: srv.URL}}
+	discord := NewDiscord(conf, config.Endpoint{Name: "example", Discord: config.Discord{Enabled: true}})
+
+	if err := discord.Raise(t.Context(), testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var payload discordMessage
+	if err := json.Unmarshal([]byte((<-reqs).body), &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("expected a single embed, got %+v", payload)
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != "[ERROR] example: no new block" || embed.Color != 0xe01e5a {
+		t.Fatalf("unexpected embed: %+v", embed)
+	}
+	if len(embed.Fields) != 1 || embed.Fields[0].Name != "block" || embed.Fields[0].Value != "10" {
+		t.Fatalf("unexpected fields: %+v", embed.Fields)
+	}
+
+	msg := testMessage()
+	msg.Status = StatusResolved
+	if err := discord.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte((<-reqs).body), &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if payload.Embeds[0].Color != 0x2eb67d {
+		t.Fatalf("expected a resolved color, got %x", payload.Embeds[0].Color)
+	}
+}
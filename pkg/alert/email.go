@@ -0,0 +1,286 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const (
+	defaultSMTPPort    = 587
+	defaultSMTPTLSPort = 465
+)
+
+// emailEntry is a message as rendered in an email, with its metadata sorted by key.
+type emailEntry struct {
+	Name     string
+	Monitor  string
+	Status   Status
+	Severity Severity
+	Message  string
+	Time     time.Time
+	Metadata []metadataField
+}
+
+var emailText = template.Must(template.New("email").Parse(`{{ range . -}}
+[{{ .Status }}] [{{ .Severity }}] {{ .Name }} at {{ .Time.Format "2006-01-02 15:04:05 MST" }}
+{{ .Message }}
+Monitor: {{ .Monitor }}
+{{ range .Metadata }}{{ .Key }}: {{ .Value }}
+{{ end }}
+{{ end }}`))
+
+var emailHTML = htmltemplate.Must(htmltemplate.New("email").Parse(`<html><body>
+{{ range . -}}
+<h3>[{{ .Status }}] [{{ .Severity }}] {{ .Name }}</h3>
+<p>{{ .Message }}</p>
+<table>
+<tr><th align="left">Time</th><td>{{ .Time.Format "2006-01-02 15:04:05 MST" }}</td></tr>
+<tr><th align="left">Monitor</th><td>{{ .Monitor }}</td></tr>
+{{ range .Metadata }}<tr><th align="left">{{ .Key }}</th><td>{{ .Value }}</td></tr>
+{{ end }}</table>
+{{ end -}}
+</body></html>
+`))
+
+// NewEmail returns an email channel using the endpoint's configuration, with unset fields taken from the global configuration.
+func NewEmail(conf *config.Config, endpoint config.Endpoint) *Email {
+	return newEmail(conf, "email", endpoint.Email.Or(conf.Email))
+}
+
+func newEmail(conf *config.Config, name string, settings config.Email) *Email {
+	if settings.Port == 0 {
+		settings.Port = defaultSMTPPort
+		if settings.TLS == config.EmailTLSImplicit {
+			settings.Port = defaultSMTPTLSPort
+		}
+	}
+	return &Email{
+		settings: settings,
+		name:     name,
+		conf:     conf,
+	}
+}
+
+// Email sends alerts through an SMTP server. With a digest window, its delivery queue collects the alerts
+// raised within the window, and they are sent together in a single email once it has passed, instead of one
+// email per alert.
+type Email struct {
+	settings  config.Email
+	tlsConfig *tls.Config // Defaults to verifying the certificate of Host
+	name      string
+	conf      *config.Config
+}
+
+func (e *Email) Name() string {
+	return e.name
+}
+
+func (e *Email) MinSeverity() Severity {
+	return e.settings.MinSeverity
+}
+
+func (e *Email) Raise(ctx context.Context, msg Message) error {
+	return e.RaiseDigest(ctx, []Message{msg})
+}
+
+// DigestWindow returns the window whose alerts are sent in a single email.
+func (e *Email) DigestWindow() time.Duration {
+	return e.settings.Digest
+}
+
+// RaiseDigest sends msgs together in a single email.
+func (e *Email) RaiseDigest(ctx context.Context, msgs []Message) error {
+	entries := make([]emailEntry, 0, len(msgs))
+	for _, msg := range msgs {
+		entries = append(entries, newEmailEntry(redactMessage(e.conf, msg)))
+	}
+	return e.send(ctx, entries)
+}
+
+func newEmailEntry(msg Message) emailEntry {
+	raised := msg.raised
+	if raised.IsZero() {
+		raised = time.Now()
+	}
+	return emailEntry{
+		Name:     msg.Name,
+		Monitor:  msg.Monitor,
+		Status:   msg.Status,
+		Severity: msg.Severity.OrDefault(),
+		Message:  msg.Message,
+		Time:     raised,
+		Metadata: metadataFields(msg),
+	}
+}
+
+func emailSubject(entries []emailEntry) string {
+	if len(entries) == 1 {
+		entry := entries[0]
+		return fmt.Sprintf("[%s] [%s] %s: %s", strings.ToUpper(string(entry.Status)), entry.Severity, entry.Name, entry.Message)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !slices.Contains(names, entry.Name) {
+			names = append(names, entry.Name)
+		}
+	}
+	return fmt.Sprintf("%d alerts for %s", len(entries), strings.Join(names, ", "))
+}
+
+// render returns the email with entries as plain text and HTML alternatives.
+func (e *Email) render(entries []emailEntry) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	header := textproto.MIMEHeader{}
+	header.Set("From", e.settings.From)
+	header.Set("To", strings.Join(e.settings.To, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", emailSubject(entries)))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		fmt.Fprintf(buf, "%s: %s\r\n", key, header.Get(key))
+	}
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		execute     func(*bytes.Buffer) error
+	}{
+		{"text/plain; charset=utf-8", func(w *bytes.Buffer) error { return emailText.Execute(w, entries) }},
+		{"text/html; charset=utf-8", func(w *bytes.Buffer) error { return emailHTML.Execute(w, entries) }},
+	} {
+		body := &bytes.Buffer{}
+		if err := part.execute(body); err != nil {
+			return nil, errors.Wrap(err, "failed to render email")
+		}
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to render email")
+		}
+		if _, err := w.Write(body.Bytes()); err != nil {
+			return nil, errors.Wrap(err, "failed to render email")
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to render email")
+	}
+	return buf.Bytes(), nil
+}
+
+// send delivers a single email with entries to every recipient.
+func (e *Email) send(ctx context.Context, entries []emailEntry) error {
+	body, err := e.render(entries)
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(e.settings.From)
+	if err != nil {
+		return errors.Wrap(err, "invalid from address")
+	}
+	var to []string
+	for _, recipient := range e.settings.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return errors.Wrapf(err, "invalid to address %q", recipient)
+		}
+		to = append(to, address.Address)
+	}
+
+	client, err := e.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if e.settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.settings.Username, e.settings.Password, e.settings.Host)); err != nil {
+			return errors.Wrap(err, "smtp authentication failed")
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return errors.Wrap(err, "smtp server rejected sender")
+	}
+	for _, address := range to {
+		if err := client.Rcpt(address); err != nil {
+			return errors.Wrapf(err, "smtp server rejected recipient %s", address)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "failed to start email data")
+	}
+	if _, err := w.Write(body); err != nil {
+		return errors.Wrap(err, "failed to write email")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "smtp server rejected email")
+	}
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, with implicit TLS or upgrading the connection with STARTTLS as configured.
+func (e *Email) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(e.settings.Host, strconv.Itoa(e.settings.Port))
+	tlsConfig := e.tlsConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: e.settings.Host, MinVersion: tls.VersionTLS12}
+	}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if e.settings.TLS == config.EmailTLSImplicit {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to smtp server %s", address)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(e.conf.RPCTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to set smtp deadline")
+	}
+
+	client, err := smtp.NewClient(conn, e.settings.Host)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to start smtp session")
+	}
+	if e.settings.TLS == "" || e.settings.TLS == config.EmailTLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, errors.Wrap(err, "smtp starttls failed")
+		}
+	}
+	return client, nil
+}
@@ -0,0 +1,317 @@
+package alert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+type fakeMail struct {
+	from string
+	to   []string
+	auth string
+	tls  bool
+	data []byte
+}
+
+// fakeSMTP is a minimal SMTP server accepting every message, offering STARTTLS when it has a certificate.
+type fakeSMTP struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+
+	mu    sync.Mutex
+	mails []fakeMail
+}
+
+func newFakeSMTP(t *testing.T, tlsConfig *tls.Config) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeSMTP{listener: listener, tlsConfig: tlsConfig}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) received() []fakeMail {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeMail(nil), f.mails...)
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	current := fakeMail{}
+	_ = tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			_ = tp.PrintfLine("250-fake")
+			if f.tlsConfig != nil && !current.tls {
+				_ = tp.PrintfLine("250-STARTTLS")
+			}
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready")
+			conn = tls.Server(conn, f.tlsConfig)
+			tp = textproto.NewConn(conn)
+			current.tls = true
+		case "AUTH":
+			current.auth = arg
+			_ = tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			current.from = arg
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			current.to = append(current.to, arg)
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			current.data, err = tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.mails = append(f.mails, current)
+			f.mu.Unlock()
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+// emailParts returns the subject and the plain text and HTML bodies of a received email.
+func emailParts(t *testing.T, data []byte) (string, string, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("failed to parse email: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("failed to decode subject: %v", err)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("failed to parse content type: %v", err)
+	}
+	bodies := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[mediaType] = string(body)
+	}
+	return subject, bodies["text/plain"], bodies["text/html"]
+}
+
+func testEmailConfig(port int) *config.Config {
+	return &config.Config{
+		Log:        logrus.New(),
+		RPCTimeout: time.Second,
+		Email: config.Email{
+			Host: "127.0.0.1",
+			Port: port,
+			TLS:  config.EmailTLSNone,
+			From: "Monitor <monitor@example.com>",
+			To:   []string{"oncall@example.com", "Team <team@example.com>"},
+		},
+	}
+}
+
+func TestEmail_SendsPlainTextAndHTML(t *testing.T) {
+	server := newFakeSMTP(t, nil)
+	email := NewEmail(testEmailConfig(server.port()), config.Endpoint{Name: "example"})
+
+	msg := testMessage()
+	msg.Message = "no new block <script>"
+	if err := email.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mails := server.received()
+	if len(mails) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(mails))
+	}
+	if mails[0].from != "FROM:<monitor@example.com>" || len(mails[0].to) != 2 || mails[0].to[1] != "TO:<team@example.com>" {
+		t.Fatalf("unexpected envelope: %+v", mails[0])
+	}
+	subject, text, html := emailParts(t, mails[0].data)
+	if subject != "[TRIGGERED] [error] example: no new block <script>" {
+		t.Fatalf("unexpected subject: %q", subject)
+	}
+	if !strings.Contains(text, "no new block <script>") || !strings.Contains(text, "block: 10") {
+		t.Fatalf("unexpected plain text body:\n%s", text)
+	}
+	if !strings.Contains(html, "no new block &lt;script&gt;") || !strings.Contains(html, `<th align="left">block</th><td>10</td>`) {
+		t.Fatalf("unexpected HTML body:\n%s", html)
+	}
+}
+
+func TestEmail_Digest(t *testing.T) {
+	server := newFakeSMTP(t, nil)
+	conf := testEmailConfig(server.port())
+	conf.Email.Digest = 50 * time.Millisecond
+	queue := NewQueue(conf, "example", NewEmail(conf, config.Endpoint{Name: "example"}))
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go queue.Run(ctx)
+
+	msg := testMessage()
+	for i := 0; i < 3; i++ {
+		msg.Message = "flap " + strconv.Itoa(i)
+		if err := queue.Raise(t.Context(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if mails := server.received(); len(mails) != 0 {
+		t.Fatalf("expected no email before the digest window passed, got %d", len(mails))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(server.received()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	mails := server.received()
+	if len(mails) != 1 {
+		t.Fatalf("expected a single digest email, got %d", len(mails))
+	}
+	subject, text, _ := emailParts(t, mails[0].data)
+	if subject != "3 alerts for example" {
+		t.Fatalf("unexpected subject: %q", subject)
+	}
+	for i := 0; i < 3; i++ {
+		if !strings.Contains(text, "flap "+strconv.Itoa(i)) {
+			t.Fatalf("expected digest to contain flap %d:\n%s", i, text)
+		}
+	}
+}
+
+func TestEmail_DigestSentOnStop(t *testing.T) {
+	server := newFakeSMTP(t, nil)
+	conf := testEmailConfig(server.port())
+	conf.Email.Digest = time.Hour
+	queue := NewQueue(conf, "example", NewEmail(conf, config.Endpoint{Name: "example"}))
+	for range 2 {
+		if err := queue.Raise(t.Context(), testMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	queue.Run(ctx)
+
+	mails := server.received()
+	if len(mails) != 1 {
+		t.Fatalf("expected the pending digest to be sent on stop, got %d emails", len(mails))
+	}
+	if subject, _, _ := emailParts(t, mails[0].data); subject != "2 alerts for example" {
+		t.Fatalf("unexpected subject: %q", subject)
+	}
+}
+
+func TestEmail_FailedDigestDeadLettered(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	conf := testEmailConfig(port)
+	conf.Email.Digest = 10 * time.Millisecond
+	conf.Delivery = testDeliveryConfig(t).Delivery
+	conf.Delivery.QueueSize = 2
+	queue := NewQueue(conf, "example", NewEmail(conf, config.Endpoint{Name: "example"}))
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go queue.Run(ctx)
+
+	for range 2 {
+		if err := queue.Raise(t.Context(), testMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for queue.Status().DeadLettered < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if status := queue.Status(); status.Queued != 0 || status.Failures != 3 || status.DeadLettered != 2 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	entries := readDeadLetters(t, conf.Delivery.DeadLetterFile)
+	if len(entries) != 2 || entries[0].Attempts != 3 || entries[0].Channel != "email" {
+		t.Fatalf("expected both alerts of the digest in the dead-letter file, got %+v", entries)
+	}
+}
+
+func TestEmail_StartTLSAndAuth(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+	server := newFakeSMTP(t, &tls.Config{Certificates: srv.TLS.Certificates})
+
+	conf := testEmailConfig(server.port())
+	conf.Email.TLS = config.EmailTLSStartTLS
+	conf.Email.Username = "monitor"
+	conf.Email.Password = "secret"
+	email := NewEmail(conf, config.Endpoint{Name: "example"})
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	email.tlsConfig = &tls.Config{RootCAs: roots, ServerName: "example.com"}
+
+	if err := email.Raise(t.Context(), testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mails := server.received()
+	if len(mails) != 1 || !mails[0].tls || !strings.HasPrefix(mails[0].auth, "PLAIN ") {
+		t.Fatalf("expected an authenticated email over TLS, got %+v", mails)
+	}
+}
@@ -0,0 +1,75 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// maxErrorBodySize limits how much of an error response is included in the returned error.
+const maxErrorBodySize = 1024
+
+// metadataField is a metadata entry of a message, formatted for display.
+type metadataField struct {
+	Key   string
+	Value string
+}
+
+// metadataFields returns the metadata of msg sorted by key, so that channels render it in a stable order.
+func metadataFields(msg Message) []metadataField {
+	var fields []metadataField
+	for _, key := range slices.Sorted(maps.Keys(msg.Metadata)) {
+		fields = append(fields, metadataField{Key: key, Value: fmt.Sprint(msg.Metadata[key])})
+	}
+	return fields
+}
+
+// summary returns the one line summary of msg shown by chat channels.
+func summary(msg Message) string {
+	if msg.Resolved() {
+		return fmt.Sprintf("[RESOLVED] %s: %s", msg.Name, msg.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(msg.Severity.OrDefault())), msg.Name, msg.Message)
+}
+
+// postJSON posts body encoded as JSON to target, and returns an error including the start of the
+// response if its status is not 2xx. The URL is left out of errors, as it may embed credentials.
+func postJSON(ctx context.Context, client *http.Client, service string, target string, header http.Header, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode %s request", service)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return errors.Newf("failed to create %s request", service)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return errors.Wrapf(err, "failed to send %s request", service)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("%s returned status %d: %s", service, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
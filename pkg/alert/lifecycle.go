@@ -0,0 +1,139 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Lifecycle tracks a single alert, keyed by endpoint and monitor, through
+// trigger -> ongoing -> resolved so that channels are notified once when a
+// problem starts and once when it recovers, rather than on every poll.
+type Lifecycle struct {
+	log           logrus.Ext1FieldLogger
+	alertChannels []Alert
+	endpoint      string
+	monitor       string
+
+	active bool
+	since  time.Time
+	last   Message
+	peak   Severity // Highest severity raised while active
+
+	silenced  bool                // The last raise was suppressed by a silence, so it is raised again once the silence ends
+	delivered map[string]struct{} // Names of the channels a trigger was raised on while active, which the resolution is raised on
+	failed    bool                // Every channel failed to raise the last trigger, so it is raised again on the next trigger
+
+	deadLettered atomic.Bool // A delivery queue gave up on a trigger, so it is raised again on the next trigger
+}
+
+// NewLifecycle returns the lifecycle of an alert raised on alertChannels. The lifecycle is tracked by the
+// channel sets among them, so that its alert is resolved once its monitor stops for good, and takes over the
+// alert left active by the monitor it replaces on a restart.
+func NewLifecycle(logger logrus.Ext1FieldLogger, alertChannels []Alert, endpoint string, monitor string) *Lifecycle {
+	out := &Lifecycle{
+		log:           logger,
+		alertChannels: alertChannels,
+		endpoint:      endpoint,
+		monitor:       monitor,
+	}
+	for _, alertChannel := range alertChannels {
+		if c, ok := alertChannel.(*Channels); ok {
+			c.track(out)
+		}
+	}
+	return out
+}
+
+// takeOver takes over the state of the lifecycle of a stopped monitor that this lifecycle's monitor replaces.
+func (l *Lifecycle) takeOver(previous *Lifecycle) {
+	l.active = previous.active
+	l.since = previous.since
+	l.last = previous.last
+	l.peak = previous.peak
+	l.silenced = previous.silenced
+	l.delivered = previous.delivered
+	l.failed = previous.failed
+	l.deadLettered.Store(previous.deadLettered.Load())
+}
+
+// Trigger raises msg on all channels if the alert is not already active.
+// While the alert is ongoing, repeated triggers are only logged unless the severity changes,
+// the previous raise was silenced, or every channel failed to raise it.
+func (l *Lifecycle) Trigger(ctx context.Context, msg Message) error {
+	msg.Name = l.endpoint
+	msg.Monitor = l.monitor
+	msg.Status = StatusTriggered
+	msg.deadLettered = func() { l.deadLettered.Store(true) }
+
+	if l.deadLettered.Swap(false) && l.active {
+		l.log.WithField("message", msg.Message).Warn("alert was not delivered, raising it again")
+		l.failed = true
+	}
+	if l.active && msg.Severity == l.last.Severity && !l.silenced && !l.failed {
+		l.log.WithFields(logrus.Fields{
+			"since":   l.since,
+			"message": msg.Message,
+		}).Debug("alert ongoing, not raising again")
+		l.last = msg
+		return nil
+	}
+
+	if !l.active {
+		l.since = time.Now()
+		l.peak = msg.Severity
+		l.delivered = map[string]struct{}{}
+	} else if !l.peak.AtLeast(msg.Severity) {
+		l.peak = msg.Severity
+	}
+	l.active = true
+	l.last = msg
+
+	wasSilenced := l.silenced
+	silenced, raised, err := raise(ctx, l.log, l.alertChannels, msg)
+	l.silenced = silenced
+	l.failed = err != nil && len(raised) == 0
+	if l.silenced && !wasSilenced {
+		l.log.WithField("message", msg.Message).Info("alert silenced, raising once the silence ends")
+	}
+	if l.failed {
+		l.log.WithField("message", msg.Message).Warn("alert not raised on any channel, raising again on the next trigger")
+	}
+	for _, name := range raised {
+		l.delivered[name] = struct{}{}
+	}
+	return err
+}
+
+// Resolve notifies the channels that the alert was raised on that it has recovered, whatever their severity and
+// routes, so that every channel alerted is resolved even if the alert escalated to a severity routed elsewhere.
+// It is a no-op if no alert is active.
+func (l *Lifecycle) Resolve(ctx context.Context) error {
+	if !l.active {
+		return nil
+	}
+	l.active = false
+	l.silenced = false
+	l.failed = false
+	l.deadLettered.Store(false)
+	if len(l.delivered) == 0 {
+		l.log.WithField("since", l.since).Info("alert resolved, it was never raised")
+		return nil
+	}
+
+	msg := l.last
+	msg.Status = StatusResolved
+	msg.deadLettered = nil
+	msg.Severity = l.peak
+	msg.Message = fmt.Sprintf("recovered after %s, last error: %s", time.Since(l.since).Round(time.Second), l.last.Message)
+	l.log.WithField("since", l.since).Info("alert resolved")
+	return raiseNamed(ctx, l.log, l.alertChannels, msg, l.delivered)
+}
+
+// Active returns true if the alert has been triggered and not yet resolved.
+func (l *Lifecycle) Active() bool {
+	return l.active
+}
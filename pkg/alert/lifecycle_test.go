@@ -0,0 +1,222 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeChannel records every message it is asked to raise, and fails to raise them while err is set.
+type fakeChannel struct {
+	name        string // Defaults to fake
+	msgs        []Message
+	minSeverity Severity
+	err         error
+}
+
+func (f *fakeChannel) Raise(ctx context.Context, msg Message) error {
+	f.msgs = append(f.msgs, msg)
+	return f.err
+}
+
+func (f *fakeChannel) Name() string {
+	if f.name == "" {
+		return "fake"
+	}
+	return f.name
+}
+
+func (f *fakeChannel) MinSeverity() Severity { return f.minSeverity }
+
+func newTestLifecycle(ch *fakeChannel) *Lifecycle {
+	return NewLifecycle(logrus.New(), []Alert{ch}, "example", "execution::BlockNumberMonitor::example")
+}
+
+func TestLifecycle_TriggerOnce(t *testing.T) {
+	ch := &fakeChannel{}
+	l := newTestLifecycle(ch)
+
+	for i := 0; i < 3; i++ {
+		if err := l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(ch.msgs) != 1 {
+		t.Fatalf("expected 1 raised message, got %d", len(ch.msgs))
+	}
+	if !l.Active() {
+		t.Fatal("expected alert to be active")
+	}
+
+	msg := ch.msgs[0]
+	if msg.Status != StatusTriggered {
+		t.Fatalf("expected triggered status, got %q", msg.Status)
+	}
+	if got, want := msg.DedupKey(), "example/execution::BlockNumberMonitor::example"; got != want {
+		t.Fatalf("unexpected dedup key got %q want %q", got, want)
+	}
+}
+
+func TestLifecycle_ResolveAfterTrigger(t *testing.T) {
+	ch := &fakeChannel{}
+	l := newTestLifecycle(ch)
+
+	if err := l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := l.Resolve(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := l.Resolve(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ch.msgs) != 2 {
+		t.Fatalf("expected trigger and resolve messages, got %d", len(ch.msgs))
+	}
+	resolved := ch.msgs[1]
+	if !resolved.Resolved() {
+		t.Fatalf("expected resolved status, got %q", resolved.Status)
+	}
+	if resolved.DedupKey() != ch.msgs[0].DedupKey() {
+		t.Fatalf("resolve dedup key %q does not match trigger %q", resolved.DedupKey(), ch.msgs[0].DedupKey())
+	}
+	if !strings.Contains(resolved.Message, "recovered") {
+		t.Fatalf("unexpected resolve message: %q", resolved.Message)
+	}
+	if l.Active() {
+		t.Fatal("expected alert to be inactive after resolve")
+	}
+}
+
+func TestLifecycle_ResolveWithoutTrigger_Noop(t *testing.T) {
+	ch := &fakeChannel{}
+	l := newTestLifecycle(ch)
+
+	if err := l.Resolve(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ch.msgs) != 0 {
+		t.Fatalf("expected no messages, got %d", len(ch.msgs))
+	}
+}
+
+func TestLifecycle_RetriggerAfterResolve(t *testing.T) {
+	ch := &fakeChannel{}
+	l := newTestLifecycle(ch)
+
+	_ = l.Trigger(t.Context(), Message{Message: "down", Severity: Error})
+	_ = l.Resolve(t.Context())
+	_ = l.Trigger(t.Context(), Message{Message: "down again", Severity: Error})
+
+	if len(ch.msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(ch.msgs))
+	}
+	if ch.msgs[2].Status != StatusTriggered {
+		t.Fatalf("expected new trigger after resolve, got %q", ch.msgs[2].Status)
+	}
+}
+
+func TestLifecycle_FailedTriggerRaisedAgain(t *testing.T) {
+	ch := &fakeChannel{err: errors.New("connection refused")}
+	l := newTestLifecycle(ch)
+
+	if err := l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error}); err == nil {
+		t.Fatal("expected an error when the channel fails")
+	}
+	ch.err = nil
+	if err := l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	if len(ch.msgs) != 2 {
+		t.Fatalf("expected the failed trigger to be raised once more, got %d raises", len(ch.msgs))
+	}
+
+	_ = l.Resolve(t.Context())
+	if len(ch.msgs) != 3 || !ch.msgs[2].Resolved() {
+		t.Fatalf("expected the delivered trigger to be resolved, got %d messages", len(ch.msgs))
+	}
+}
+
+func TestLifecycle_FailedTriggerNotResolved(t *testing.T) {
+	ch := &fakeChannel{err: errors.New("connection refused")}
+	l := newTestLifecycle(ch)
+
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	ch.err = nil
+	if err := l.Resolve(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ch.msgs) != 1 {
+		t.Fatalf("expected no resolve for a trigger that was never raised, got %d messages", len(ch.msgs))
+	}
+}
+
+func TestLifecycle_RoutesBySeverity(t *testing.T) {
+	slack := &fakeChannel{name: "slack", minSeverity: Warning}
+	pager := &fakeChannel{name: "pagerduty", minSeverity: Critical}
+	l := NewLifecycle(logrus.New(), []Alert{slack, pager}, "example", "execution::PeerCountMonitor::example")
+
+	_ = l.Trigger(t.Context(), Message{Message: "low peers", Severity: Warning})
+	if len(slack.msgs) != 1 || len(pager.msgs) != 0 {
+		t.Fatalf("expected warning on slack only, got slack=%d pager=%d", len(slack.msgs), len(pager.msgs))
+	}
+
+	_ = l.Trigger(t.Context(), Message{Message: "no peers", Severity: Critical})
+	if len(slack.msgs) != 2 || len(pager.msgs) != 1 {
+		t.Fatalf("expected escalation on both channels, got slack=%d pager=%d", len(slack.msgs), len(pager.msgs))
+	}
+
+	// Dropping back to a warning must still resolve the page
+	_ = l.Trigger(t.Context(), Message{Message: "low peers", Severity: Warning})
+	_ = l.Resolve(t.Context())
+	if len(pager.msgs) != 2 || !pager.msgs[1].Resolved() {
+		t.Fatalf("expected page to be resolved, got %+v", pager.msgs)
+	}
+	if pager.msgs[1].Severity != Critical {
+		t.Fatalf("expected resolution at peak severity, got %q", pager.msgs[1].Severity)
+	}
+}
+
+func TestChannels_HandoverKeepsActiveAlert(t *testing.T) {
+	ch := &fakeChannel{}
+	channels := NewChannels(logrus.New(), []Alert{ch})
+	l := NewLifecycle(logrus.New(), []Alert{channels}, "example", "execution::BlockNumberMonitor::example")
+	stale := NewLifecycle(logrus.New(), []Alert{channels}, "example", "execution::SyncStatusMonitor::example")
+	for _, lifecycle := range []*Lifecycle{l, stale} {
+		if err := lifecycle.Trigger(t.Context(), Message{Message: "down", Severity: Error}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The block number monitor is restarted, the sync status monitor is no longer configured
+	channels.Handover()
+	restarted := NewLifecycle(logrus.New(), []Alert{channels}, "example", "execution::BlockNumberMonitor::example")
+	if !restarted.Active() {
+		t.Fatal("expected the restarted lifecycle to take over the active alert")
+	}
+	if err := restarted.Trigger(t.Context(), Message{Message: "down", Severity: Error}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ch.msgs) != 2 {
+		t.Fatalf("expected the ongoing alert not to be raised again, got %d messages", len(ch.msgs))
+	}
+
+	if err := channels.ResolveHandedOver(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ch.msgs) != 3 || ch.msgs[2].Status != StatusResolved || ch.msgs[2].Monitor != "execution::SyncStatusMonitor::example" {
+		t.Fatalf("expected only the alert of the monitor not restarted to be resolved, got %+v", ch.msgs)
+	}
+
+	if err := channels.ResolveAll(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ch.msgs) != 4 || ch.msgs[3].Status != StatusResolved || ch.msgs[3].Monitor != "execution::BlockNumberMonitor::example" {
+		t.Fatalf("expected the restarted monitor's alert to be resolved once stopped for good, got %+v", ch.msgs)
+	}
+}
@@ -0,0 +1,208 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const (
+	opsgenieURL    = "https://api.opsgenie.com"
+	opsgenieEUURL  = "https://api.eu.opsgenie.com"
+	opsgenieSource = "eth-monitor"
+
+	// Opsgenie rejects longer messages and aliases
+	maxOpsgenieMessageLength = 130
+	maxOpsgenieAliasLength   = 512
+)
+
+var defaultOpsgeniePriorities = map[Severity]string{
+	Critical: "P1",
+	Error:    "P2",
+	Warning:  "P3",
+	Info:     "P5",
+}
+
+// NewOpsgenie returns an Opsgenie channel using the endpoint's configuration, with unset fields taken from the global configuration.
+func NewOpsgenie(conf *config.Config, endpoint config.Endpoint) Opsgenie {
+	og := endpoint.Opsgenie
+	if og.APIKey == "" {
+		og.APIKey = conf.Opsgenie.APIKey
+	}
+	if og.Region == "" {
+		og.Region = conf.Opsgenie.Region
+	}
+	if og.Responders == nil {
+		og.Responders = conf.Opsgenie.Responders
+	}
+	if og.Tags == nil {
+		og.Tags = conf.Opsgenie.Tags
+	}
+	if og.Priorities == nil {
+		og.Priorities = conf.Opsgenie.Priorities
+	}
+	if og.MinSeverity == "" {
+		og.MinSeverity = conf.Opsgenie.MinSeverity
+	}
+	return newOpsgenie(conf, "opsgenie", og)
+}
+
+func newOpsgenie(conf *config.Config, name string, og config.Opsgenie) Opsgenie {
+	out := Opsgenie{
+		client:      &http.Client{Timeout: conf.RPCTimeout},
+		url:         opsgenieURL,
+		apiKey:      og.APIKey,
+		responders:  og.Responders,
+		tags:        og.Tags,
+		priorities:  og.Priorities,
+		name:        name,
+		minSeverity: og.MinSeverity,
+		conf:        conf,
+		raised:      &opsgenieRaised{priorities: map[string]string{}},
+	}
+	if og.Region == config.OpsgenieRegionEU {
+		out.url = opsgenieEUURL
+	}
+	return out
+}
+
+type Opsgenie struct {
+	client     *http.Client
+	url        string
+	apiKey     string
+	responders []config.OpsgenieResponder
+	tags       []string
+	priorities map[Severity]string
+
+	name        string
+	minSeverity Severity
+	conf        *config.Config
+	raised      *opsgenieRaised
+}
+
+// opsgenieRaised holds the priority of each open alert by alias, as Opsgenie keeps the priority an alert was
+// created with when it is raised again.
+type opsgenieRaised struct {
+	mu         sync.Mutex
+	priorities map[string]string
+}
+
+type opsgenieAlert struct {
+	Message     string                     `json:"message"`
+	Alias       string                     `json:"alias"`
+	Description string                     `json:"description"`
+	Responders  []config.OpsgenieResponder `json:"responders,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Details     map[string]string          `json:"details,omitempty"`
+	Entity      string                     `json:"entity"`
+	Source      string                     `json:"source"`
+	Priority    string                     `json:"priority"`
+}
+
+type opsgeniePriority struct {
+	Priority string `json:"priority"`
+}
+
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note"`
+}
+
+func (o Opsgenie) Name() string {
+	return o.name
+}
+
+func (o Opsgenie) MinSeverity() Severity {
+	return o.minSeverity
+}
+
+// priority returns the Opsgenie priority of severity.
+func (o Opsgenie) priority(severity Severity) string {
+	severity = severity.OrDefault()
+	if priority, ok := o.priorities[severity]; ok {
+		return priority
+	}
+	return defaultOpsgeniePriorities[severity]
+}
+
+// Raise creates an Opsgenie alert, or closes it once resolved. Both use the message's dedup key as the alias,
+// so that repeated raises while the alert is open are counted on the same alert instead of creating new ones.
+// Opsgenie keeps the priority the alert was created with when it is raised again, so the priority is updated
+// when the alert escalates to a higher one.
+func (o Opsgenie) Raise(ctx context.Context, msg Message) error {
+	msg = redactMessage(o.conf, msg)
+	alias := truncate(msg.DedupKey(), maxOpsgenieAliasLength)
+	path := "/v2/alerts/" + url.PathEscape(alias)
+
+	if msg.Resolved() {
+		err := o.post(ctx, path+"/close?identifierType=alias", opsgenieClose{Source: opsgenieSource, Note: msg.Message})
+		if err == nil {
+			o.raised.mu.Lock()
+			delete(o.raised.priorities, alias)
+			o.raised.mu.Unlock()
+		}
+		return err
+	}
+
+	var details map[string]string
+	if fields := metadataFields(msg); len(fields) > 0 {
+		details = make(map[string]string, len(fields))
+		for _, field := range fields {
+			details[field.Key] = field.Value
+		}
+	}
+	priority := o.priority(msg.Severity)
+	err := o.post(ctx, "/v2/alerts", opsgenieAlert{
+		Message:     truncate(fmt.Sprintf("%s: %s", msg.Name, msg.Message), maxOpsgenieMessageLength),
+		Alias:       alias,
+		Description: msg.Message,
+		Responders:  o.responders,
+		Tags:        o.tags,
+		Details:     details,
+		Entity:      msg.Name,
+		Source:      opsgenieSource,
+		Priority:    priority,
+	})
+	if err != nil {
+		return err
+	}
+
+	o.raised.mu.Lock()
+	previous, ok := o.raised.priorities[alias]
+	o.raised.mu.Unlock()
+	// P1 is the highest priority. A lower priority is kept, as the alert stays open at its highest priority
+	if ok && priority >= previous {
+		return nil
+	}
+	if ok {
+		err = o.post(ctx, path+"/priority?identifierType=alias", opsgeniePriority{Priority: priority})
+		if err != nil {
+			return errors.Wrap(err, "failed to update the opsgenie alert priority")
+		}
+	}
+	o.raised.mu.Lock()
+	o.raised.priorities[alias] = priority
+	o.raised.mu.Unlock()
+	return nil
+}
+
+func (o Opsgenie) post(ctx context.Context, path string, body any) error {
+	header := http.Header{}
+	header.Set("Authorization", "GenieKey "+o.apiKey)
+	return postJSON(ctx, o.client, "opsgenie", o.url+path, header, body)
+}
+
+// truncate returns s cut to at most length runes.
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	return string(runes[:length])
+}
@@ -0,0 +1,203 @@
+package alert
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+type opsgenieRequest struct {
+	path  string
+	query string
+	auth  string
+	body  []byte
+}
+
+// fakeOpsgenie stands in for the Opsgenie alert API, keeping the open alerts by alias.
+type fakeOpsgenie struct {
+	mu       sync.Mutex
+	requests []opsgenieRequest
+	open     map[string]int    // Number of creates of each open alert
+	priority map[string]string // Priority of each open alert
+}
+
+func newFakeOpsgenie(t *testing.T) (*fakeOpsgenie, *httptest.Server) {
+	t.Helper()
+	f := &fakeOpsgenie{open: map[string]int{}, priority: map[string]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, opsgenieRequest{path: r.URL.Path, query: r.URL.RawQuery, auth: r.Header.Get("Authorization"), body: body})
+
+		if r.Header.Get("Authorization") != "GenieKey example-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/alerts":
+			var alert opsgenieAlert
+			if err := json.Unmarshal(body, &alert); err != nil || alert.Message == "" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			if f.open[alert.Alias] == 0 {
+				f.priority[alert.Alias] = alert.Priority
+			}
+			f.open[alert.Alias]++
+		case strings.HasSuffix(r.URL.Path, "/priority") && r.URL.Query().Get("identifierType") == "alias":
+			alias := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/alerts/"), "/priority")
+			var priority opsgeniePriority
+			if err := json.Unmarshal(body, &priority); err != nil || f.open[alias] == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			f.priority[alias] = priority.Priority
+		case strings.HasSuffix(r.URL.Path, "/close") && r.URL.Query().Get("identifierType") == "alias":
+			alias := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/alerts/"), "/close")
+			delete(f.open, alias)
+			delete(f.priority, alias)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func newTestOpsgenie(srv *httptest.Server, conf *config.Config, endpoint config.Endpoint) Opsgenie {
+	og := NewOpsgenie(conf, endpoint)
+	og.url = srv.URL
+	return og
+}
+
+func TestOpsgenie_AliasDedupAndClose(t *testing.T) {
+	f, srv := newFakeOpsgenie(t)
+	conf := &config.Config{RPCTimeout: time.Second, Opsgenie: config.Opsgenie{APIKey: "example-key"}}
+	og := newTestOpsgenie(srv, conf, config.Endpoint{Name: "example"})
+
+	msg := testMessage()
+	for i := 0; i < 2; i++ {
+		if err := og.Raise(t.Context(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(f.open) != 1 || f.open[msg.DedupKey()] != 2 {
+		t.Fatalf("expected repeated raises on one alias, got %v", f.open)
+	}
+
+	var created opsgenieAlert
+	if err := json.Unmarshal(f.requests[0].body, &created); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	if created.Priority != "P2" || created.Entity != "example" || created.Details["block"] != "10" {
+		t.Fatalf("unexpected alert: %+v", created)
+	}
+
+	msg.Status = StatusResolved
+	if err := og.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.open) != 0 {
+		t.Fatalf("expected alert to be closed, got %v", f.open)
+	}
+}
+
+func TestOpsgenie_EscalationUpdatesPriority(t *testing.T) {
+	f, srv := newFakeOpsgenie(t)
+	conf := &config.Config{RPCTimeout: time.Second, Opsgenie: config.Opsgenie{APIKey: "example-key"}}
+	og := newTestOpsgenie(srv, conf, config.Endpoint{Name: "example"})
+
+	msg := testMessage()
+	for _, severity := range []Severity{Warning, Critical, Error} {
+		msg.Severity = severity
+		if err := og.Raise(t.Context(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := f.priority[msg.DedupKey()]; got != "P1" {
+		t.Fatalf("expected the escalated priority P1, got %q", got)
+	}
+	var updates int
+	for _, request := range f.requests {
+		if strings.HasSuffix(request.path, "/priority") {
+			updates++
+		}
+	}
+	if updates != 1 {
+		t.Fatalf("expected a single priority update, got %d", updates)
+	}
+
+	// Once closed, the alert is created again at its own priority
+	msg.Status = StatusResolved
+	if err := og.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg.Status = StatusTriggered
+	msg.Severity = Warning
+	if err := og.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.priority[msg.DedupKey()]; got != "P3" {
+		t.Fatalf("expected the new alert at P3, got %q", got)
+	}
+}
+
+func TestOpsgenie_EndpointOverride(t *testing.T) {
+	f, srv := newFakeOpsgenie(t)
+	conf := &config.Config{RPCTimeout: time.Second, Opsgenie: config.Opsgenie{
+		APIKey:     "example-key",
+		Region:     config.OpsgenieRegionEU,
+		Tags:       []string{"global"},
+		Responders: []config.OpsgenieResponder{{Type: "team", Name: "infra"}},
+	}}
+	endpoint := config.Endpoint{Name: "example", Opsgenie: config.Opsgenie{
+		Enabled:    true,
+		Tags:       []string{"mainnet"},
+		Priorities: map[Severity]string{Error: "P4"},
+	}}
+
+	if og := NewOpsgenie(conf, endpoint); og.url != opsgenieEUURL {
+		t.Fatalf("expected the EU API, got %s", og.url)
+	}
+	og := newTestOpsgenie(srv, conf, endpoint)
+	msg := testMessage()
+	msg.Message = strings.Repeat("x", 200)
+	if err := og.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var created opsgenieAlert
+	if err := json.Unmarshal(f.requests[0].body, &created); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	if created.Priority != "P4" || len(created.Tags) != 1 || created.Tags[0] != "mainnet" {
+		t.Fatalf("expected endpoint priority and tags, got %+v", created)
+	}
+	if len(created.Responders) != 1 || created.Responders[0].Name != "infra" {
+		t.Fatalf("expected global responders, got %+v", created.Responders)
+	}
+	if len(created.Message) != maxOpsgenieMessageLength || created.Description != msg.Message {
+		t.Fatalf("expected a truncated message and full description, got %q", created.Message)
+	}
+}
+
+func TestOpsgenie_Error(t *testing.T) {
+	_, srv := newFakeOpsgenie(t)
+	conf := &config.Config{RPCTimeout: time.Second, Opsgenie: config.Opsgenie{APIKey: "wrong-key"}}
+	og := newTestOpsgenie(srv, conf, config.Endpoint{Name: "example"})
+
+	err := og.Raise(t.Context(), testMessage())
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+}
@@ -8,38 +8,64 @@ import (
 )
 
 func NewPagerduty(conf *config.Config, endpoint config.Endpoint) Pagerduty {
-	out := Pagerduty{
-		Service: endpoint.Pagerduty.Service,
+	pd := endpoint.Pagerduty
+	if pd.MinSeverity == "" {
+		pd.MinSeverity = conf.Pagerduty.MinSeverity
 	}
-	if endpoint.Pagerduty.RoutingKey != "" {
-		out.RoutingKey = endpoint.Pagerduty.RoutingKey
-	} else {
-		out.RoutingKey = conf.Pagerduty.RoutingKey
+	if pd.RoutingKey == "" {
+		pd.RoutingKey = conf.Pagerduty.RoutingKey
 	}
+	return newPagerduty(conf, "pagerduty", pd)
+}
 
-	return out
+func newPagerduty(conf *config.Config, name string, pd config.Pagerduty) Pagerduty {
+	return Pagerduty{
+		RoutingKey:  pd.RoutingKey,
+		Service:     pd.Service,
+		name:        name,
+		minSeverity: pd.MinSeverity,
+		conf:        conf,
+	}
 }
 
 type Pagerduty struct {
-	RoutingKey string
-	Service    string
+	RoutingKey  string
+	Service     string
+	name        string
+	minSeverity Severity
+	conf        *config.Config
 }
 
-// TODO: Prevent duplicate alerts by checking if an alert is already active
-func (p Pagerduty) Raise(ctx context.Context, msg Message) error {
+func (p Pagerduty) Name() string {
+	return p.name
+}
 
-	payload := &pagerduty.V2Payload{
-		Summary:   msg.Message,
-		Severity:  string(msg.Severity),
-		Component: msg.Name,
-		Source:    p.Service,
-		Details:   msg.Metadata,
-	}
+func (p Pagerduty) MinSeverity() Severity {
+	return p.minSeverity
+}
 
-	_, err := pagerduty.ManageEventWithContext(ctx, pagerduty.V2Event{
+// Raise triggers or resolves a PagerDuty incident, using the message's dedup key
+// so that repeated triggers update the same incident.
+func (p Pagerduty) Raise(ctx context.Context, msg Message) error {
+	msg = redactMessage(p.conf, msg)
+	event := pagerduty.V2Event{
 		RoutingKey: p.RoutingKey,
 		Action:     "trigger",
-		Payload:    payload,
-	})
+		DedupKey:   msg.DedupKey(),
+	}
+
+	if msg.Resolved() {
+		event.Action = "resolve"
+	} else {
+		event.Payload = &pagerduty.V2Payload{
+			Summary:   msg.Message,
+			Severity:  string(msg.Severity.OrDefault()),
+			Component: msg.Name,
+			Source:    p.Service,
+			Details:   msg.Metadata,
+		}
+	}
+
+	_, err := pagerduty.ManageEventWithContext(ctx, event)
 	return err
 }
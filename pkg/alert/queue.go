@@ -0,0 +1,361 @@
+package alert
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/backoff"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+)
+
+var (
+	// ErrQueueFull is returned when a message is raised on a channel whose delivery queue is full.
+	ErrQueueFull = errors.New("alert delivery queue is full")
+	// ErrQueueStopped is returned when a message is raised on a channel whose delivery queue has stopped.
+	ErrQueueStopped = errors.New("alert delivery queue is stopped")
+)
+
+// DeliveryStatus is a snapshot of the deliveries of an endpoint's alert channel.
+type DeliveryStatus struct {
+	Endpoint     string    `json:"endpoint"`
+	Channel      string    `json:"channel"`
+	Queued       int       `json:"queued"`        // Messages waiting for delivery, including the one being delivered
+	Delivered    uint64    `json:"delivered"`     // Messages delivered
+	Failures     uint64    `json:"failures"`      // Failed delivery attempts, including the ones retried successfully
+	DeadLettered uint64    `json:"dead_lettered"` // Messages given up on and written to the dead-letter log
+	LastError    string    `json:"last_error,omitempty"`
+	LastFailure  time.Time `json:"last_failure,omitzero"`
+}
+
+// Queue delivers the messages raised on a channel in the background, in the order they were raised, so that
+// a slow channel does not block the monitor raising them. A failed delivery is retried with exponential
+// backoff, and the message is written to the dead-letter log once the attempts run out. The messages of a
+// channel raising digests are delivered together at the end of each digest window.
+type Queue struct {
+	Alert
+	digest   digester // Nil if the channel raises every message on its own
+	endpoint string
+	settings config.Delivery
+	conf     *config.Config
+	log      logrus.Ext1FieldLogger
+	messages chan Message
+
+	mu      sync.Mutex
+	stopped bool
+	pending int
+	status  DeliveryStatus
+}
+
+func NewQueue(conf *config.Config, endpoint string, alertChannel Alert) *Queue {
+	settings := conf.Delivery.OrDefault()
+	return &Queue{
+		Alert:    alertChannel,
+		digest:   findDigester(alertChannel),
+		endpoint: endpoint,
+		settings: settings,
+		conf:     conf,
+		log: conf.Log.WithFields(logrus.Fields{
+			"endpoint": endpoint,
+			"channel":  alertChannel.Name(),
+		}),
+		messages: make(chan Message, settings.QueueSize),
+		status:   DeliveryStatus{Endpoint: endpoint, Channel: alertChannel.Name()},
+	}
+}
+
+// RunQueues wraps every alert channel of the endpoint in a queue, delivering its messages until ctx is done.
+// The queues are registered in deliveries while they run, unless it is nil.
+func RunQueues(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []Alert, deliveries *Deliveries) []Alert {
+	out := make([]Alert, 0, len(alertChannels))
+	for _, alertChannel := range alertChannels {
+		queue := NewQueue(conf, endpoint.Name, alertChannel)
+		if deliveries != nil {
+			deliveries.Register(queue)
+		}
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			if deliveries != nil {
+				defer deliveries.Unregister(queue)
+			}
+			queue.Run(ctx)
+		}()
+		out = append(out, queue)
+	}
+	return out
+}
+
+func (q *Queue) Accepts(msg Message) bool {
+	if inner, ok := q.Alert.(acceptor); ok {
+		return inner.Accepts(msg)
+	}
+	return true
+}
+
+func (q *Queue) Silences(msg Message) []string {
+	if inner, ok := q.Alert.(silenceable); ok {
+		return inner.Silences(msg)
+	}
+	return nil
+}
+
+// Raise queues msg for delivery. If the queue is full or stopped, msg is written to the dead-letter log instead
+// and an error is returned.
+func (q *Queue) Raise(ctx context.Context, msg Message) error {
+	msg.raised = time.Now()
+	q.mu.Lock()
+	err := ErrQueueFull
+	if q.stopped {
+		err = ErrQueueStopped
+	} else {
+		select {
+		case q.messages <- msg:
+			q.pending++
+			err = nil
+		default:
+		}
+	}
+	q.mu.Unlock()
+
+	if err != nil {
+		q.deadLetter([]Message{msg}, 0, err)
+		return err
+	}
+	metrics.AlertQueueLength.WithLabelValues(q.Name()).Inc()
+	return nil
+}
+
+// Run delivers the queued messages until ctx is done. It then stops accepting messages, and keeps delivering
+// the queued ones, and the pending digest, for up to the drain timeout, so that a reload or shutdown does not
+// drop the alerts raised just before it. The messages still queued then are written to the dead-letter log.
+func (q *Queue) Run(ctx context.Context) {
+	// Deliveries, including the one in flight when ctx is done, are only cut short once the drain times out
+	deliverCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	stopDrainTimer := context.AfterFunc(ctx, func() {
+		time.AfterFunc(q.settings.DrainTimeout, cancel)
+	})
+	defer stopDrainTimer()
+
+	var (
+		digest []Message
+		window <-chan time.Time
+	)
+	for {
+		select {
+		case msg := <-q.messages:
+			if q.digest == nil {
+				q.deliver(deliverCtx, []Message{msg})
+				continue
+			}
+			digest = append(digest, msg)
+			if window == nil {
+				window = time.After(q.digest.DigestWindow())
+			}
+		case <-window:
+			q.deliver(deliverCtx, digest)
+			digest, window = nil, nil
+		case <-ctx.Done():
+			q.drain(deliverCtx, digest)
+			return
+		}
+	}
+}
+
+// drain stops accepting messages, and delivers the pending digest with the messages still queued until ctx is done.
+func (q *Queue) drain(ctx context.Context, digest []Message) {
+	q.mu.Lock()
+	q.stopped = true
+	pending := q.pending
+	q.mu.Unlock()
+	if pending > 0 {
+		q.log.WithField("queued", pending).Info("delivering the queued alerts before stopping")
+	}
+	for {
+		select {
+		case msg := <-q.messages:
+			if q.digest != nil {
+				digest = append(digest, msg)
+				continue
+			}
+			q.deliver(ctx, []Message{msg})
+		default:
+			if len(digest) > 0 {
+				q.deliver(ctx, digest)
+			}
+			return
+		}
+	}
+}
+
+// Status returns the delivery status of the channel.
+func (q *Queue) Status() DeliveryStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := q.status
+	out.Queued = q.pending
+	return out
+}
+
+// deliver raises msgs on the channel, together if it raises digests, retrying with backoff until they are
+// delivered, the attempts run out or ctx is done.
+func (q *Queue) deliver(ctx context.Context, msgs []Message) {
+	defer q.done(len(msgs))
+	if ctx.Err() != nil {
+		q.deadLetter(msgs, 0, ErrQueueStopped)
+		return
+	}
+	b := backoff.New(q.settings.MinBackoff, q.settings.MaxBackoff)
+	for attempt := 1; ; attempt++ {
+		err := q.raise(ctx, msgs)
+		q.record(len(msgs), err)
+		if err == nil {
+			for _, msg := range msgs {
+				metrics.AlertsRaised.WithLabelValues(q.Name(), string(msg.Status)).Inc()
+			}
+			return
+		}
+		metrics.AlertErrors.WithLabelValues(q.Name()).Inc()
+		if attempt >= q.settings.MaxAttempts || ctx.Err() != nil {
+			q.deadLetter(msgs, attempt, err)
+			return
+		}
+
+		delay := b.Next()
+		metrics.AlertRetries.WithLabelValues(q.Name()).Inc()
+		q.log.WithError(err).WithFields(logrus.Fields{
+			"attempt":  attempt,
+			"alerts":   len(msgs),
+			"retry_in": delay,
+		}).Warn("failed to deliver alert, retrying")
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			q.deadLetter(msgs, attempt, err)
+			return
+		}
+	}
+}
+
+func (q *Queue) raise(ctx context.Context, msgs []Message) error {
+	if q.digest != nil {
+		return q.digest.RaiseDigest(ctx, msgs)
+	}
+	return q.Alert.Raise(ctx, msgs[0])
+}
+
+// record counts a delivery attempt of n messages.
+func (q *Queue) record(n int, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err == nil {
+		q.status.Delivered += uint64(n)
+		return
+	}
+	q.status.Failures++
+	q.status.LastError = q.conf.Redact(err.Error())
+	q.status.LastFailure = time.Now()
+}
+
+// done marks n dequeued messages as handled.
+func (q *Queue) done(n int) {
+	q.mu.Lock()
+	q.pending -= n
+	q.mu.Unlock()
+	metrics.AlertQueueLength.WithLabelValues(q.Name()).Sub(float64(n))
+}
+
+// deadLetter gives up on delivering msgs after attempts, logging them and appending them to the dead-letter file.
+// The lifecycles that triggered them are told, so that they raise their alerts again.
+func (q *Queue) deadLetter(msgs []Message, attempts int, err error) {
+	q.mu.Lock()
+	q.status.DeadLettered += uint64(len(msgs))
+	q.mu.Unlock()
+	metrics.AlertsDeadLettered.WithLabelValues(q.Name()).Add(float64(len(msgs)))
+
+	for _, msg := range msgs {
+		if msg.deadLettered != nil {
+			msg.deadLettered()
+		}
+		entry := newDeadLetter(q.conf, q.Name(), msg, attempts, err)
+		q.log.WithError(err).WithFields(logrus.Fields{
+			"attempts": attempts,
+			"status":   msg.Status,
+			"monitor":  msg.Monitor,
+			"message":  entry.Message,
+		}).Error("failed to deliver alert, giving up")
+		if q.settings.DeadLetterFile == "" {
+			continue
+		}
+		if err := appendDeadLetter(q.settings.DeadLetterFile, entry); err != nil {
+			q.log.WithError(err).Error("failed to write alert to the dead-letter file")
+		}
+	}
+}
+
+// Deliveries holds the running delivery queues so that their status can be reported.
+type Deliveries struct {
+	mu     sync.RWMutex
+	queues map[*Queue]struct{}
+}
+
+func NewDeliveries() *Deliveries {
+	return &Deliveries{
+		queues: map[*Queue]struct{}{},
+	}
+}
+
+func (d *Deliveries) Register(q *Queue) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queues[q] = struct{}{}
+}
+
+func (d *Deliveries) Unregister(q *Queue) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.queues, q)
+}
+
+// Statuses returns the delivery status of every channel, sorted by endpoint and channel. The queues of the
+// same endpoint and channel, such as those of the head lag monitor, are reported together.
+func (d *Deliveries) Statuses() []DeliveryStatus {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	merged := map[[2]string]*DeliveryStatus{}
+	for q := range d.queues {
+		status := q.Status()
+		key := [2]string{status.Endpoint, status.Channel}
+		out, ok := merged[key]
+		if !ok {
+			merged[key] = &status
+			continue
+		}
+		out.Queued += status.Queued
+		out.Delivered += status.Delivered
+		out.Failures += status.Failures
+		out.DeadLettered += status.DeadLettered
+		if status.LastFailure.After(out.LastFailure) {
+			out.LastError = status.LastError
+			out.LastFailure = status.LastFailure
+		}
+	}
+	out := make([]DeliveryStatus, 0, len(merged))
+	for _, status := range merged {
+		out = append(out, *status)
+	}
+	slices.SortFunc(out, func(a, b DeliveryStatus) int {
+		if c := strings.Compare(a.Endpoint, b.Endpoint); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Channel, b.Channel)
+	})
+	return out
+}
@@ -0,0 +1,250 @@
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// flakyChannel fails the first failures raises, and sends the messages it delivers on delivered.
+type flakyChannel struct {
+	mu        sync.Mutex
+	failures  int
+	delivered chan Message
+}
+
+func (f *flakyChannel) Raise(ctx context.Context, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures != 0 {
+		f.failures--
+		return errors.New("service unavailable")
+	}
+	f.delivered <- msg
+	return nil
+}
+
+func (f *flakyChannel) Name() string { return "flaky" }
+
+func (f *flakyChannel) MinSeverity() Severity { return "" }
+
+func testDeliveryConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		Log: logrus.New(),
+		Delivery: config.Delivery{
+			QueueSize:      1,
+			MaxAttempts:    3,
+			MinBackoff:     time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			DeadLetterFile: filepath.Join(t.TempDir(), "dead-letters.jsonl"),
+		},
+	}
+}
+
+func readDeadLetters(t *testing.T, file string) []deadLetter {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("failed to open dead-letter file: %v", err)
+	}
+	defer f.Close()
+	var out []deadLetter
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to decode dead letter %q: %v", scanner.Text(), err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestQueue_RetriesUntilDelivered(t *testing.T) {
+	conf := testDeliveryConfig(t)
+	ch := &flakyChannel{failures: 2, delivered: make(chan Message, 1)}
+	deliveries := NewDeliveries()
+	waitGroup := &sync.WaitGroup{}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer func() {
+		cancel()
+		waitGroup.Wait()
+	}()
+	channels := RunQueues(ctx, waitGroup, conf, config.Endpoint{Name: "example"}, []Alert{ch}, deliveries)
+
+	if err := RaiseAll(ctx, conf.Log, channels, testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case msg := <-ch.delivered:
+		if msg.Message != "no new block" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for deliveries.Statuses()[0].Queued != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	status := deliveries.Statuses()[0]
+	if status.Endpoint != "example" || status.Channel != "flaky" || status.Delivered != 1 || status.Failures != 2 || status.DeadLettered != 0 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if status.LastError != "service unavailable" {
+		t.Fatalf("expected the last error to be reported, got %q", status.LastError)
+	}
+}
+
+func TestQueue_DeadLettersAfterMaxAttempts(t *testing.T) {
+	conf := testDeliveryConfig(t)
+	ch := &flakyChannel{failures: -1}
+	queue := NewQueue(conf, "example", ch)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go queue.Run(ctx)
+
+	if err := queue.Raise(ctx, testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for queue.Status().DeadLettered == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if status := queue.Status(); status.Failures != 3 || status.DeadLettered != 1 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	entries := readDeadLetters(t, conf.Delivery.DeadLetterFile)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Channel != "flaky" || entry.Endpoint != "example" || entry.Attempts != 3 || entry.Error != "service unavailable" || entry.Metadata["block"] != "10" {
+		t.Fatalf("unexpected dead letter: %+v", entry)
+	}
+}
+
+func TestQueue_FullQueueDoesNotBlock(t *testing.T) {
+	conf := testDeliveryConfig(t)
+	// Without Run, the queue is never drained
+	queue := NewQueue(conf, "example", &flakyChannel{})
+
+	if err := queue.Raise(t.Context(), testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := RaiseAll(t.Context(), conf.Log, []Alert{queue}, testMessage())
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected a full queue error, got %v", err)
+	}
+	if status := queue.Status(); status.Queued != 1 || status.DeadLettered != 1 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if entries := readDeadLetters(t, conf.Delivery.DeadLetterFile); len(entries) != 1 || entries[0].Attempts != 0 {
+		t.Fatalf("expected the dropped message in the dead-letter file, got %+v", entries)
+	}
+}
+
+func TestQueue_StopDeliversQueued(t *testing.T) {
+	conf := testDeliveryConfig(t)
+	ch := &flakyChannel{delivered: make(chan Message, 1)}
+	queue := NewQueue(conf, "example", ch)
+	if err := queue.Raise(t.Context(), testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	queue.Run(ctx)
+
+	if status := queue.Status(); status.Queued != 0 || status.Delivered != 1 || status.DeadLettered != 0 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if len(ch.delivered) != 1 {
+		t.Fatal("expected the queued message to be delivered before stopping")
+	}
+	if err := queue.Raise(t.Context(), testMessage()); !errors.Is(err, ErrQueueStopped) {
+		t.Fatalf("expected a stopped queue error, got %v", err)
+	}
+}
+
+func TestQueue_StopDeadLettersAfterDrainTimeout(t *testing.T) {
+	conf := testDeliveryConfig(t)
+	conf.Delivery.QueueSize = 2
+	conf.Delivery.MaxAttempts = 100
+	conf.Delivery.MinBackoff = time.Hour
+	conf.Delivery.MaxBackoff = time.Hour
+	conf.Delivery.DrainTimeout = 10 * time.Millisecond
+	queue := NewQueue(conf, "example", &flakyChannel{failures: -1})
+	for range 2 {
+		if err := queue.Raise(t.Context(), testMessage()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the queue to stop")
+	}
+
+	if status := queue.Status(); status.Queued != 0 || status.Failures != 1 || status.DeadLettered != 2 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	entries := readDeadLetters(t, conf.Delivery.DeadLetterFile)
+	if len(entries) != 2 || entries[0].Attempts != 1 || entries[1].Attempts != 0 {
+		t.Fatalf("expected both messages in the dead-letter file, got %+v", entries)
+	}
+}
+
+func TestLifecycle_DeadLetteredTriggerRaisedAgain(t *testing.T) {
+	conf := testDeliveryConfig(t)
+	conf.Delivery.MaxAttempts = 1
+	ch := &flakyChannel{failures: 1, delivered: make(chan Message, 1)}
+	queue := NewQueue(conf, "example", ch)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go queue.Run(ctx)
+	l := NewLifecycle(conf.Log, []Alert{queue}, "example", "execution::BlockNumberMonitor::example")
+
+	if err := l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for queue.Status().DeadLettered == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if queue.Status().DeadLettered != 1 {
+		t.Fatal("expected the trigger to be dead-lettered")
+	}
+
+	if err := l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case msg := <-ch.delivered:
+		if msg.Status != StatusTriggered {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the trigger to be raised again")
+	}
+}
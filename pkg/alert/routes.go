@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// NewRoutedChannels returns the channels of every receiver, each only raising the alerts of the endpoint
+// that the configured routes send to its receiver.
+func NewRoutedChannels(conf *config.Config, endpoint config.Endpoint) ([]Alert, error) {
+	var out []Alert
+	for _, receiver := range conf.Receivers {
+		channels, err := newReceiverChannels(conf, receiver)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create channels of receiver %s", receiver.Name)
+		}
+		for _, channel := range channels {
+			out = append(out, routedAlert{
+				Alert:    channel,
+				conf:     conf,
+				endpoint: endpoint,
+				receiver: receiver.Name,
+			})
+		}
+	}
+	return out, nil
+}
+
+func newReceiverChannels(conf *config.Config, receiver config.Receiver) ([]Alert, error) {
+	var out []Alert
+	if receiver.HasPagerduty() {
+		out = append(out, newPagerduty(conf, receiver.Name+"/pagerduty", receiver.Pagerduty))
+	}
+	if receiver.HasOpsgenie() {
+		out = append(out, newOpsgenie(conf, receiver.Name+"/opsgenie", receiver.Opsgenie))
+	}
+	if receiver.HasEmail() {
+		out = append(out, newEmail(conf, receiver.Name+"/email", receiver.Email))
+	}
+	if receiver.HasSlack() {
+		out = append(out, newSlack(conf, receiver.Name+"/slack", receiver.Slack.MinSeverity, receiver.Slack))
+	}
+	if receiver.HasDiscord() {
+		out = append(out, newDiscord(conf, receiver.Name+"/discord", receiver.Discord))
+	}
+	if receiver.HasTelegram() {
+		out = append(out, newTelegram(conf, receiver.Name+"/telegram", receiver.Telegram))
+	}
+	if receiver.HasTeams() {
+		out = append(out, newTeams(conf, receiver.Name+"/teams", receiver.Teams))
+	}
+	if receiver.HasWebhook() {
+		webhook, err := newWebhook(conf, receiver.Name+"/webhook", receiver.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, webhook)
+	}
+	return out, nil
+}
+
+// routedAlert is a channel of a receiver, accepting only the messages routed to that receiver.
+type routedAlert struct {
+	Alert
+	conf     *config.Config
+	endpoint config.Endpoint
+	receiver string
+}
+
+func (r routedAlert) Accepts(msg Message) bool {
+	return slices.Contains(r.conf.RouteReceivers(r.endpoint, monitorKind(msg.Monitor), msg.Severity.OrDefault()), r.receiver)
+}
+
+func (r routedAlert) unwrap() Alert {
+	return r.Alert
+}
+
+// monitorKind returns the kind of a monitor from its name, such as PeerCountMonitor for execution::PeerCountMonitor::node.
+func monitorKind(monitor string) string {
+	parts := strings.Split(monitor, "::")
+	if len(parts) < 2 {
+		return monitor
+	}
+	return parts[1]
+}
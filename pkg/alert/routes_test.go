@@ -0,0 +1,83 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func TestRoutedChannels(t *testing.T) {
+	conf := &config.Config{
+		Log: logrus.New(),
+		Routes: []config.Route{
+			{Match: config.RouteMatch{Monitors: []string{"PeerCountMonitor"}}, Receivers: []string{"team"}, Continue: true},
+			{Match: config.RouteMatch{MinSeverity: Critical}, Receivers: []string{"oncall"}},
+		},
+	}
+	endpoint := config.Endpoint{Name: "example"}
+	team, oncall := &fakeChannel{name: "team"}, &fakeChannel{name: "oncall"}
+	channels := []Alert{
+		routedAlert{Alert: team, conf: conf, endpoint: endpoint, receiver: "team"},
+		routedAlert{Alert: oncall, conf: conf, endpoint: endpoint, receiver: "oncall"},
+	}
+
+	peers := NewLifecycle(logrus.New(), channels, endpoint.Name, "execution::PeerCountMonitor::example")
+	if err := peers.Trigger(t.Context(), Message{Message: "low peers", Severity: Warning}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(team.msgs) != 1 || len(oncall.msgs) != 0 {
+		t.Fatalf("expected warning to reach only the team, got team=%d oncall=%d", len(team.msgs), len(oncall.msgs))
+	}
+
+	blocks := NewLifecycle(logrus.New(), channels, endpoint.Name, "execution::BlockNumberMonitor::example")
+	if err := blocks.Trigger(t.Context(), Message{Message: "no new block", Severity: Critical}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := blocks.Resolve(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(team.msgs) != 1 || len(oncall.msgs) != 2 {
+		t.Fatalf("expected block alert and resolution to reach only oncall, got team=%d oncall=%d", len(team.msgs), len(oncall.msgs))
+	}
+}
+
+func TestRoutedChannels_ResolveReachesEscalatedFrom(t *testing.T) {
+	conf := &config.Config{
+		Log: logrus.New(),
+		Routes: []config.Route{
+			{Match: config.RouteMatch{MinSeverity: Critical}, Receivers: []string{"pagerduty"}},
+			{Match: config.RouteMatch{MinSeverity: Warning}, Receivers: []string{"slack"}},
+		},
+	}
+	endpoint := config.Endpoint{Name: "example"}
+	slack, pager := &fakeChannel{name: "slack"}, &fakeChannel{name: "pagerduty"}
+	channels := []Alert{
+		routedAlert{Alert: slack, conf: conf, endpoint: endpoint, receiver: "slack"},
+		routedAlert{Alert: pager, conf: conf, endpoint: endpoint, receiver: "pagerduty"},
+	}
+
+	l := NewLifecycle(logrus.New(), channels, endpoint.Name, "execution::PeerCountMonitor::example")
+	_ = l.Trigger(t.Context(), Message{Message: "low peers", Severity: Warning})
+	_ = l.Trigger(t.Context(), Message{Message: "no peers", Severity: Critical})
+	if len(slack.msgs) != 1 || len(pager.msgs) != 1 {
+		t.Fatalf("expected the warning on slack and the escalation on pagerduty, got slack=%d pagerduty=%d", len(slack.msgs), len(pager.msgs))
+	}
+
+	if err := l.Resolve(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slack.msgs) != 2 || !slack.msgs[1].Resolved() {
+		t.Fatalf("expected the resolution on slack, got %+v", slack.msgs)
+	}
+	if len(pager.msgs) != 2 || !pager.msgs[1].Resolved() {
+		t.Fatalf("expected the resolution on pagerduty, got %+v", pager.msgs)
+	}
+}
+
+func TestMonitorKind(t *testing.T) {
+	if got := monitorKind("consensus::FinalityMonitor::node"); got != "FinalityMonitor" {
+		t.Fatalf("unexpected kind %q", got)
+	}
+}
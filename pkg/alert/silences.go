@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// Silencer returns the names of the silences currently suppressing the alerts of an endpoint.
+type Silencer interface {
+	Active(endpoint config.Endpoint) []string
+}
+
+// silenceable is implemented by channels whose messages may be suppressed by silences.
+type silenceable interface {
+	Silences(msg Message) []string
+}
+
+// silencedAlert suppresses the triggered messages of its channel while the endpoint is silenced. Resolutions
+// are always raised, so that incidents opened before a silence started are closed.
+type silencedAlert struct {
+	Alert
+	silencer Silencer
+	endpoint config.Endpoint
+}
+
+// WithSilences wraps the alert channels of endpoint, so that RaiseAll does not raise their messages while
+// silencer reports a silence of the endpoint.
+func WithSilences(alertChannels []Alert, silencer Silencer, endpoint config.Endpoint) []Alert {
+	out := make([]Alert, 0, len(alertChannels))
+	for _, alertChannel := range alertChannels {
+		out = append(out, &silencedAlert{Alert: alertChannel, silencer: silencer, endpoint: endpoint})
+	}
+	return out
+}
+
+func (a *silencedAlert) Accepts(msg Message) bool {
+	if inner, ok := a.Alert.(acceptor); ok {
+		return inner.Accepts(msg)
+	}
+	return true
+}
+
+func (a *silencedAlert) unwrap() Alert {
+	return a.Alert
+}
+
+func (a *silencedAlert) Silences(msg Message) []string {
+	if msg.Resolved() {
+		return nil
+	}
+	return a.silencer.Active(a.endpoint)
+}
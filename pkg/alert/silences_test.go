@@ -0,0 +1,73 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// fakeSilencer reports the silences in names as active for every endpoint.
+type fakeSilencer struct {
+	names []string
+}
+
+func (f *fakeSilencer) Active(endpoint config.Endpoint) []string { return f.names }
+
+func TestLifecycle_SilencedTriggerRaisedAfterSilenceEnds(t *testing.T) {
+	ch := &fakeChannel{}
+	silencer := &fakeSilencer{names: []string{"upgrade"}}
+	l := NewLifecycle(logrus.New(), WithSilences([]Alert{ch}, silencer, config.Endpoint{Name: "example"}),
+		"example", "execution::BlockNumberMonitor::example")
+
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	if len(ch.msgs) != 0 {
+		t.Fatalf("expected no messages while silenced, got %d", len(ch.msgs))
+	}
+	if !l.Active() {
+		t.Fatal("expected alert to be active while silenced")
+	}
+
+	silencer.names = nil
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	if len(ch.msgs) != 1 || ch.msgs[0].Status != StatusTriggered {
+		t.Fatalf("expected a single trigger once the silence ended, got %+v", ch.msgs)
+	}
+}
+
+func TestLifecycle_ResolveDeliveredDuringSilence(t *testing.T) {
+	ch := &fakeChannel{}
+	silencer := &fakeSilencer{}
+	l := NewLifecycle(logrus.New(), WithSilences([]Alert{ch}, silencer, config.Endpoint{Name: "example"}),
+		"example", "execution::BlockNumberMonitor::example")
+
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	silencer.names = []string{"upgrade"}
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Critical})
+	_ = l.Resolve(t.Context())
+
+	if len(ch.msgs) != 2 || !ch.msgs[1].Resolved() {
+		t.Fatalf("expected the trigger raised before the silence to be resolved, got %+v", ch.msgs)
+	}
+}
+
+func TestLifecycle_SilencedAlertResolvedQuietly(t *testing.T) {
+	ch := &fakeChannel{}
+	silencer := &fakeSilencer{names: []string{"upgrade"}}
+	l := NewLifecycle(logrus.New(), WithSilences([]Alert{ch}, silencer, config.Endpoint{Name: "example"}),
+		"example", "execution::BlockNumberMonitor::example")
+
+	_ = l.Trigger(t.Context(), Message{Message: "no new block", Severity: Error})
+	silencer.names = nil
+	_ = l.Resolve(t.Context())
+
+	if len(ch.msgs) != 0 {
+		t.Fatalf("expected no resolution of an alert that was never raised, got %+v", ch.msgs)
+	}
+	if l.Active() {
+		t.Fatal("expected alert to be inactive after resolve")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/numbergroup/eth-monitor/pkg/config"
 	"github.com/sirupsen/logrus"
@@ -12,96 +11,98 @@ import (
 )
 
 func NewSlack(conf *config.Config, endpoint config.Endpoint) Slack {
+	minSeverity := endpoint.Slack.MinSeverity
+	if minSeverity == "" {
+		minSeverity = conf.Slack.MinSeverity
+	}
+	// The endpoint's Slack configuration is preferred, the global one is the fallback
+	return newSlack(conf, "slack", minSeverity, endpoint.Slack, conf.Slack)
+}
+
+func newSlack(conf *config.Config, name string, minSeverity Severity, targets ...config.Slack) Slack {
 	return Slack{
-		conf:     conf,
-		log:      conf.Log,
-		endpoint: endpoint,
+		conf:        conf,
+		log:         conf.Log,
+		name:        name,
+		targets:     targets,
+		minSeverity: minSeverity,
 	}
 }
 
 type Slack struct {
-	conf     *config.Config
-	endpoint config.Endpoint
-	log      logrus.Ext1FieldLogger
+	conf        *config.Config
+	targets     []config.Slack // Tried in order, the first one with a webhook or a channel and token is used
+	log         logrus.Ext1FieldLogger
+	name        string
+	minSeverity Severity
 }
 
-func (s Slack) Raise(ctx context.Context, msg Message) error {
-	// Handle Slack, first try the endpoint's Slack configuration, then the global Slack configuration
-	// Webhook is prioritized, but if it's empty, we can use the channel and token
-
-	slackMsg := &slack.WebhookMessage{
-		Text: s.formatMessage(msg),
-		Attachments: []slack.Attachment{
-			{
-				Color:  s.severityColor(msg.Severity),
-				Fields: s.buildMetadataFields(msg),
-			},
-		},
-	}
-
-	// Priority: Endpoint Webhook -> Endpoint Channel/Token -> Global Webhook -> Global Channel/Token
-
-	// 1. Try endpoint webhook URL first
-	if len(s.endpoint.Slack.WebhookURL) != 0 {
-		err := slack.PostWebhookContext(ctx, s.endpoint.Slack.WebhookURL, slackMsg)
-		if err != nil {
-			s.log.WithError(err).WithField("endpoint", msg.Name).Error("failed to send Slack alert via endpoint webhook")
-		}
-		return err
-	}
+func (s Slack) Name() string {
+	return s.name
+}
 
-	// 2. Try endpoint channel/token if available
-	if len(s.endpoint.Slack.Channel) != 0 && len(s.endpoint.Slack.Token) != 0 {
-		api := slack.New(s.endpoint.Slack.Token)
-		_, _, err := api.PostMessageContext(ctx, s.endpoint.Slack.Channel,
-			slack.MsgOptionText(s.formatMessage(msg), false),
-			slack.MsgOptionAttachments(slack.Attachment{
-				Color:  s.severityColor(msg.Severity),
-				Fields: s.buildMetadataFields(msg),
-			}),
-		)
-		if err != nil {
-			s.log.WithError(err).WithField("endpoint", msg.Name).Error("failed to send Slack alert via endpoint channel")
-		}
-		return err
-	}
+func (s Slack) MinSeverity() Severity {
+	return s.minSeverity
+}
 
-	// 3. Try global webhook URL
-	if len(s.conf.Slack.WebhookURL) != 0 {
-		err := slack.PostWebhookContext(ctx, s.conf.Slack.WebhookURL, slackMsg)
-		if err != nil {
-			s.log.WithError(err).WithField("endpoint", msg.Name).Error("failed to send Slack alert via global webhook")
+func (s Slack) Raise(ctx context.Context, msg Message) error {
+	msg = redactMessage(s.conf, msg)
+
+	// Webhook is prioritized over the channel and token of the same target
+	for _, target := range s.targets {
+		if len(target.WebhookURL) != 0 {
+			slackMsg := &slack.WebhookMessage{
+				Text: s.formatMessage(msg),
+				Attachments: []slack.Attachment{
+					{
+						Color:  s.messageColor(msg),
+						Fields: s.buildMetadataFields(msg),
+					},
+				},
+			}
+			err := slack.PostWebhookContext(ctx, target.WebhookURL, slackMsg)
+			if err != nil {
+				s.log.WithError(err).WithField("endpoint", msg.Name).Error("failed to send Slack alert via webhook")
+			}
+			return err
 		}
-		return err
-	}
 
-	// 4. Try global channel/token as last resort
-	if len(s.conf.Slack.Channel) != 0 && len(s.conf.Slack.Token) != 0 {
-		api := slack.New(s.conf.Slack.Token)
-		_, _, err := api.PostMessageContext(ctx, s.conf.Slack.Channel,
-			slack.MsgOptionText(s.formatMessage(msg), false),
-			slack.MsgOptionAttachments(slack.Attachment{
-				Color:  s.severityColor(msg.Severity),
-				Fields: s.buildMetadataFields(msg),
-			}),
-		)
-		if err != nil {
-			s.log.WithError(err).WithField("endpoint", msg.Name).Error("failed to send Slack alert via global channel")
+		if len(target.Channel) != 0 && len(target.Token) != 0 {
+			api := slack.New(target.Token)
+			_, _, err := api.PostMessageContext(ctx, target.Channel,
+				slack.MsgOptionText(s.formatMessage(msg), false),
+				slack.MsgOptionAttachments(slack.Attachment{
+					Color:  s.messageColor(msg),
+					Fields: s.buildMetadataFields(msg),
+				}),
+			)
+			if err != nil {
+				s.log.WithError(err).WithField("endpoint", msg.Name).Error("failed to send Slack alert via channel")
+			}
+			return err
 		}
-		return err
 	}
 
 	return errors.New("no valid Slack configuration found for alerting")
 }
 
 func (s Slack) formatMessage(msg Message) string {
-	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(msg.Severity)), msg.Name, msg.Message)
+	return summary(msg)
+}
+
+func (s Slack) messageColor(msg Message) string {
+	if msg.Resolved() {
+		return "good"
+	}
+	return s.severityColor(msg.Severity)
 }
 
 func (s Slack) severityColor(severity Severity) string {
 	switch severity {
-	case Error:
+	case Critical, Error:
 		return "danger"
+	case Info:
+		return "#439fe0"
 	default:
 		return "warning"
 	}
@@ -0,0 +1,123 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// NewTeams returns a Microsoft Teams channel using the endpoint's configuration, with unset fields taken from the global configuration.
+func NewTeams(conf *config.Config, endpoint config.Endpoint) Teams {
+	return newTeams(conf, "teams", endpoint.Teams.Or(conf.Teams))
+}
+
+func newTeams(conf *config.Config, name string, teams config.Teams) Teams {
+	return Teams{
+		client:      &http.Client{Timeout: conf.RPCTimeout},
+		webhookURL:  teams.WebhookURL,
+		name:        name,
+		minSeverity: teams.MinSeverity,
+		conf:        conf,
+	}
+}
+
+// Teams posts alerts as adaptive cards, accepted by both Teams workflows and incoming webhook connectors.
+type Teams struct {
+	client     *http.Client
+	webhookURL string
+
+	name        string
+	minSeverity Severity
+	conf        *config.Config
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+}
+
+// teamsElement is a TextBlock or, with Facts set, a FactSet of an adaptive card.
+type teamsElement struct {
+	Type   string      `json:"type"`
+	Text   string      `json:"text,omitempty"`
+	Weight string      `json:"weight,omitempty"`
+	Size   string      `json:"size,omitempty"`
+	Color  string      `json:"color,omitempty"`
+	Wrap   bool        `json:"wrap,omitempty"`
+	Facts  []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func (t Teams) Name() string {
+	return t.name
+}
+
+func (t Teams) MinSeverity() Severity {
+	return t.minSeverity
+}
+
+func (t Teams) Raise(ctx context.Context, msg Message) error {
+	msg = redactMessage(t.conf, msg)
+	body := []teamsElement{
+		{Type: "TextBlock", Text: summary(msg), Weight: "Bolder", Size: "Medium", Color: t.messageColor(msg), Wrap: true},
+	}
+	if facts := t.buildMetadataFields(msg); len(facts) > 0 {
+		body = append(body, teamsElement{Type: "FactSet", Facts: facts})
+	}
+	return postJSON(ctx, t.client, "teams", t.webhookURL, nil, teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	})
+}
+
+// messageColor returns the adaptive card color of the message title.
+func (t Teams) messageColor(msg Message) string {
+	if msg.Resolved() {
+		return "Good"
+	}
+	return t.severityColor(msg.Severity)
+}
+
+func (t Teams) severityColor(severity Severity) string {
+	switch severity.OrDefault() {
+	case Critical, Error:
+		return "Attention"
+	case Info:
+		return "Accent"
+	default:
+		return "Warning"
+	}
+}
+
+func (t Teams) buildMetadataFields(msg Message) []teamsFact {
+	var facts []teamsFact
+	for _, field := range metadataFields(msg) {
+		facts = append(facts, teamsFact{Title: field.Key, Value: field.Value})
+	}
+	return facts
+}
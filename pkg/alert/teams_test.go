@@ -0,0 +1,36 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func TestTeams_AdaptiveCard(t *testing.T) {
+	srv, reqs := newWebhookServer(t, http.StatusAccepted)
+	conf := &config.Config{RPCTimeout: time.Second}
+	teams := NewTeams(conf, config.Endpoint{Name: "example", Teams: config.Teams{Enabled: true, WebhookURL: srv.URL}})
+
+	msg := testMessage()
+	msg.Severity = Warning
+	if err := teams.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var payload teamsMessage
+	if err := json.Unmarshal([]byte((<-reqs).body), &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if payload.Type != "message" || len(payload.Attachments) != 1 || payload.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	body := payload.Attachments[0].Content.Body
+	if len(body) != 2 || body[0].Text != "[WARNING] example: no new block" || body[0].Color != "Warning" {
+		t.Fatalf("unexpected card body: %+v", body)
+	}
+	if len(body[1].Facts) != 1 || body[1].Facts[0] != (teamsFact{Title: "block", Value: "10"}) {
+		t.Fatalf("unexpected facts: %+v", body[1].Facts)
+	}
+}
@@ -0,0 +1,114 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const (
+	telegramURL = "https://api.telegram.org"
+
+	// Telegram rejects longer messages
+	maxTelegramMessageLength = 4096
+)
+
+// NewTelegram returns a Telegram channel using the endpoint's configuration, with unset fields taken from the global configuration.
+func NewTelegram(conf *config.Config, endpoint config.Endpoint) Telegram {
+	return newTelegram(conf, "telegram", endpoint.Telegram.Or(conf.Telegram))
+}
+
+func newTelegram(conf *config.Config, name string, telegram config.Telegram) Telegram {
+	return Telegram{
+		client:      &http.Client{Timeout: conf.RPCTimeout},
+		url:         telegramURL,
+		botToken:    telegram.BotToken,
+		chatID:      telegram.ChatID,
+		name:        name,
+		minSeverity: telegram.MinSeverity,
+		conf:        conf,
+	}
+}
+
+// Telegram sends alerts to a chat through the Bot API, as HTML formatted messages.
+type Telegram struct {
+	client   *http.Client
+	url      string
+	botToken string
+	chatID   string
+
+	name        string
+	minSeverity Severity
+	conf        *config.Config
+}
+
+type telegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+func (t Telegram) Name() string {
+	return t.name
+}
+
+func (t Telegram) MinSeverity() Severity {
+	return t.minSeverity
+}
+
+func (t Telegram) Raise(ctx context.Context, msg Message) error {
+	msg = redactMessage(t.conf, msg)
+	return postJSON(ctx, t.client, "telegram", t.url+"/bot"+t.botToken+"/sendMessage", nil, telegramMessage{
+		ChatID:                t.chatID,
+		Text:                  t.formatMessage(msg),
+		ParseMode:             "HTML",
+		DisableWebPagePreview: true,
+	})
+}
+
+func (t Telegram) formatMessage(msg Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s <b>%s</b>", t.messageEmoji(msg), html.EscapeString(summary(msg)))
+	for _, field := range t.buildMetadataFields(msg) {
+		b.WriteString("\n" + field)
+	}
+	text := b.String()
+	if len([]rune(text)) > maxTelegramMessageLength {
+		// Cutting through the markup would make Telegram reject the message, so send the summary alone
+		text = truncate(fmt.Sprintf("%s %s", t.messageEmoji(msg), html.EscapeString(summary(msg))), maxTelegramMessageLength)
+	}
+	return text
+}
+
+func (t Telegram) messageEmoji(msg Message) string {
+	if msg.Resolved() {
+		return "✅"
+	}
+	return t.severityEmoji(msg.Severity)
+}
+
+func (t Telegram) severityEmoji(severity Severity) string {
+	switch severity.OrDefault() {
+	case Critical:
+		return "🔴"
+	case Error:
+		return "🟠"
+	case Info:
+		return "🔵"
+	default:
+		return "🟡"
+	}
+}
+
+func (t Telegram) buildMetadataFields(msg Message) []string {
+	var fields []string
+	for _, field := range metadataFields(msg) {
+		fields = append(fields, fmt.Sprintf("<b>%s</b>: %s", html.EscapeString(field.Key), html.EscapeString(field.Value)))
+	}
+	return fields
+}
@@ -0,0 +1,53 @@
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func TestTelegram_SendMessage(t *testing.T) {
+	var (
+		path    string
+		message telegramMessage
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&message)
+	}))
+	defer srv.Close()
+
+	conf := &config.Config{RPCTimeout: time.Second, Telegram: config.Telegram{BotToken: "123:secret", ChatID: "-100"}}
+	telegram := NewTelegram(conf, config.Endpoint{Name: "example", Telegram: config.Telegram{Enabled: true, ChatID: "-200"}})
+	telegram.url = srv.URL
+
+	msg := testMessage()
+	msg.Severity = Critical
+	msg.Metadata = map[string]any{"block": 10, "<peer>": "a&b"}
+	if err := telegram.Raise(t.Context(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/bot123:secret/sendMessage" || message.ChatID != "-200" || message.ParseMode != "HTML" {
+		t.Fatalf("unexpected request to %s: %+v", path, message)
+	}
+	want := "🔴 <b>[CRITICAL] example: no new block</b>\n<b>&lt;peer&gt;</b>: a&amp;b\n<b>block</b>: 10"
+	if message.Text != want {
+		t.Fatalf("unexpected text:\n%s", message.Text)
+	}
+}
+
+func TestTelegram_ErrorHidesToken(t *testing.T) {
+	conf := &config.Config{RPCTimeout: time.Second, Telegram: config.Telegram{BotToken: "123:secret", ChatID: "-100"}}
+	telegram := NewTelegram(conf, config.Endpoint{Name: "example"})
+	telegram.url = "http://127.0.0.1:0"
+
+	err := telegram.Raise(t.Context(), testMessage())
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected an error without the bot token, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"text/template"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// NewWebhook returns a webhook channel using the endpoint's webhook configuration, with the unset fields
+// taken from the global configuration.
+func NewWebhook(conf *config.Config, endpoint config.Endpoint) (Webhook, error) {
+	return newWebhook(conf, "webhook", endpoint.Webhook.Or(conf.Webhook))
+}
+
+func newWebhook(conf *config.Config, name string, hook config.Webhook) (Webhook, error) {
+	out := Webhook{
+		client:      &http.Client{Timeout: conf.RPCTimeout},
+		name:        name,
+		url:         hook.URL,
+		method:      hook.Method,
+		headers:     hook.Headers,
+		minSeverity: hook.MinSeverity,
+		conf:        conf,
+	}
+	if out.method == "" {
+		out.method = http.MethodPost
+	}
+	if hook.Body != "" {
+		tmpl, err := hook.Template()
+		if err != nil {
+			return Webhook{}, errors.Wrap(err, "failed to parse webhook body template")
+		}
+		out.body = tmpl
+	}
+
+	return out, nil
+}
+
+type Webhook struct {
+	client  *http.Client
+	name    string
+	url     string
+	method  string
+	headers map[string]string
+	body    *template.Template // Nil sends the message encoded as JSON
+
+	minSeverity Severity
+	conf        *config.Config
+}
+
+type webhookPayload struct {
+	Name     string         `json:"name"`
+	Monitor  string         `json:"monitor"`
+	Severity Severity       `json:"severity"`
+	Status   Status         `json:"status"`
+	Message  string         `json:"message"`
+	DedupKey string         `json:"dedup_key"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+func (w Webhook) Name() string {
+	return w.name
+}
+
+func (w Webhook) MinSeverity() Severity {
+	return w.minSeverity
+}
+
+func (w Webhook) Raise(ctx context.Context, msg Message) error {
+	msg = redactMessage(w.conf, msg)
+	body, err := w.render(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.method, w.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook request")
+	}
+	if w.body == nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send webhook request")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w Webhook) render(msg Message) ([]byte, error) {
+	if w.body == nil {
+		data, err := json.Marshal(webhookPayload{
+			Name:     msg.Name,
+			Monitor:  msg.Monitor,
+			Severity: msg.Severity.OrDefault(),
+			Status:   msg.Status,
+			Message:  msg.Message,
+			DedupKey: msg.DedupKey(),
+			Metadata: msg.Metadata,
+		})
+		return data, errors.Wrap(err, "failed to encode webhook payload")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := w.body.Execute(buf, msg); err != nil {
+		return nil, errors.Wrap(err, "failed to render webhook body")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package alert
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+type webhookRequest struct {
+	method string
+	header http.Header
+	body   string
+}
+
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, chan webhookRequest) {
+	t.Helper()
+	reqs := make(chan webhookRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqs <- webhookRequest{method: r.Method, header: r.Header, body: string(body)}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, reqs
+}
+
+func testMessage() Message {
+	return Message{
+		Message:  "no new block",
+		Severity: Error,
+		Name:     "example",
+		Monitor:  "execution::BlockNumberMonitor::example",
+		Status:   StatusTriggered,
+		Metadata: map[string]any{"block": 10},
+	}
+}
+
+func TestWebhook_DefaultJSONPayload(t *testing.T) {
+	srv, reqs := newWebhookServer(t, http.StatusOK)
+	conf := &config.Config{RPCTimeout: time.Second, Webhook: config.Webhook{URL: srv.URL}}
+
+	hook, err := NewWebhook(conf, config.Endpoint{Name: "example"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hook.Raise(t.Context(), testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := <-reqs
+	if req.method != http.MethodPost {
+		t.Fatalf("expected POST, got %s", req.method)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected JSON content type, got %q", got)
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal([]byte(req.body), &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if payload.DedupKey != "example/execution::BlockNumberMonitor::example" || payload.Message != "no new block" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestWebhook_EndpointTemplate(t *testing.T) {
+	srv, reqs := newWebhookServer(t, http.StatusOK)
+	conf := &config.Config{RPCTimeout: time.Second, Webhook: config.Webhook{URL: "http://global.invalid"}}
+	endpoint := config.Endpoint{
+		Name: "example",
+		Webhook: config.Webhook{
+			URL:     srv.URL,
+			Method:  http.MethodPut,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+			Body:    `{"text": {{ printf "[%s] %s: %s" .Severity .Name .Message | json }}, "block": {{ index .Metadata "block" }}}`,
+		},
+	}
+
+	hook, err := NewWebhook(conf, endpoint)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hook.Raise(t.Context(), testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := <-reqs
+	if req.method != http.MethodPut {
+		t.Fatalf("expected PUT, got %s", req.method)
+	}
+	if got := req.header.Get("Authorization"); got != "Bearer token" {
+		t.Fatalf("expected authorization header, got %q", got)
+	}
+	if want := `{"text": "[error] example: no new block", "block": 10}`; req.body != want {
+		t.Fatalf("unexpected body got %s want %s", req.body, want)
+	}
+}
+
+func TestWebhook_EndpointFallsBackToGlobal(t *testing.T) {
+	srv, reqs := newWebhookServer(t, http.StatusOK)
+	conf := &config.Config{RPCTimeout: time.Second, Webhook: config.Webhook{
+		URL:     "http://global.invalid",
+		Method:  http.MethodPut,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}}
+	endpoint := config.Endpoint{Name: "example", Webhook: config.Webhook{URL: srv.URL}}
+
+	hook, err := NewWebhook(conf, endpoint)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hook.Raise(t.Context(), testMessage()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := <-reqs
+	if req.method != http.MethodPut {
+		t.Fatalf("expected the global method, got %s", req.method)
+	}
+	if got := req.header.Get("Authorization"); got != "Bearer token" {
+		t.Fatalf("expected the global headers, got %q", got)
+	}
+}
+
+func TestWebhook_ErrorStatus(t *testing.T) {
+	srv, _ := newWebhookServer(t, http.StatusInternalServerError)
+	conf := &config.Config{RPCTimeout: time.Second, Webhook: config.Webhook{URL: srv.URL}}
+
+	hook, err := NewWebhook(conf, config.Endpoint{Name: "example"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := hook.Raise(t.Context(), testMessage()); err == nil {
+		t.Fatal("expected error on non-2xx status")
+	}
+}
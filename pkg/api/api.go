@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
+)
+
+type handler struct {
+	log        logrus.Ext1FieldLogger
+	registry   *monitor.Registry
+	silences   *silence.Manager
+	deliveries *alert.Deliveries
+	adminToken string
+}
+
+type statusResponse struct {
+	Healthy    bool                   `json:"healthy"`
+	Monitors   []monitor.Status       `json:"monitors"`
+	Deliveries []alert.DeliveryStatus `json:"deliveries"` // Alert deliveries of each endpoint's channels
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type readyResponse struct {
+	Ready   bool     `json:"ready"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+// NewHandler returns the HTTP handler serving metrics, the Kubernetes health and
+// readiness probes, the status of every registered monitor and alert channel, and the silences.
+func NewHandler(conf *config.Config, registry *monitor.Registry, silences *silence.Manager, deliveries *alert.Deliveries) http.Handler {
+	h := &handler{
+		log:        conf.Log.WithField("name", "api"),
+		registry:   registry,
+		silences:   silences,
+		deliveries: deliveries,
+		adminToken: conf.AdminToken,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", h.healthz)
+	mux.HandleFunc("GET /readyz", h.readyz)
+	mux.HandleFunc("GET /api/v1/status", h.status)
+	mux.HandleFunc("GET /api/v1/silences", h.listSilences)
+	mux.HandleFunc("POST /api/v1/silences", h.admin(h.createSilence))
+	mux.HandleFunc("DELETE /api/v1/silences/{name}", h.admin(h.deleteSilence))
+	return mux
+}
+
+// healthz reports that the process is alive and serving requests.
+func (h *handler) healthz(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz reports ready once every registered monitor has completed its first check.
+func (h *handler) readyz(w http.ResponseWriter, r *http.Request) {
+	statuses := h.registry.Statuses()
+	out := readyResponse{Ready: len(statuses) > 0}
+	for _, status := range statuses {
+		if status.State == monitor.StateUnknown {
+			out.Ready = false
+			out.Pending = append(out.Pending, status.Name)
+		}
+	}
+
+	code := http.StatusOK
+	if !out.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	h.writeJSON(w, code, out)
+}
+
+func (h *handler) status(w http.ResponseWriter, r *http.Request) {
+	out := statusResponse{
+		Healthy:    true,
+		Monitors:   h.registry.Statuses(),
+		Deliveries: h.deliveries.Statuses(),
+	}
+	for i, status := range out.Monitors {
+		if status.State == monitor.StateFailing {
+			out.Healthy = false
+		}
+		if status.Endpoint != "" {
+			out.Monitors[i].Silences = h.silences.ActiveFor(status.Endpoint)
+		}
+	}
+	h.writeJSON(w, http.StatusOK, out)
+}
+
+func (h *handler) writeError(w http.ResponseWriter, code int, err error) {
+	h.writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func (h *handler) writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.log.WithError(err).Error("failed to write response")
+	}
+}
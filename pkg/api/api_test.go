@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
+)
+
+type fakeMonitor struct {
+	name    string
+	tracker *monitor.Tracker
+}
+
+func newFakeMonitor(name string) *fakeMonitor {
+	return &fakeMonitor{name: name, tracker: monitor.NewTracker(&config.Config{}, name, "ep")}
+}
+
+func (f *fakeMonitor) Run(ctx context.Context) {}
+func (f *fakeMonitor) Name() string            { return f.name }
+func (f *fakeMonitor) Status() monitor.Status  { return f.tracker.Status() }
+
+func serve(t *testing.T, registry *monitor.Registry, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	h := NewHandler(conf, registry, silence.NewManager(conf), alert.NewDeliveries())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHealthz(t *testing.T) {
+	rec := serve(t, monitor.NewRegistry(), "/healthz")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestReadyzWaitsForFirstCheck(t *testing.T) {
+	registry := monitor.NewRegistry()
+	a, b := newFakeMonitor("a"), newFakeMonitor("b")
+	registry.Register(a)
+	registry.Register(b)
+
+	a.tracker.Record(nil, nil)
+	rec := serve(t, registry, "/readyz")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while a monitor is pending, got %d", rec.Code)
+	}
+	var ready readyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &ready); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(ready.Pending) != 1 || ready.Pending[0] != "b" {
+		t.Fatalf("expected b to be pending, got %v", ready.Pending)
+	}
+
+	b.tracker.Record(nil, errors.New("boom"))
+	rec = serve(t, registry, "/readyz")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 once every monitor has checked, got %d", rec.Code)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	registry := monitor.NewRegistry()
+	a, b := newFakeMonitor("a"), newFakeMonitor("b")
+	registry.Register(b)
+	registry.Register(a)
+	a.tracker.Record(map[string]any{"block": 10}, nil)
+	b.tracker.Record(map[string]any{"peers": 1}, errors.New("too few peers"))
+
+	rec := serve(t, registry, "/api/v1/status")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var status statusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if status.Healthy {
+		t.Fatalf("expected unhealthy with a failing monitor")
+	}
+	if len(status.Monitors) != 2 || status.Monitors[0].Name != "a" || status.Monitors[1].Name != "b" {
+		t.Fatalf("expected monitors sorted by name, got %+v", status.Monitors)
+	}
+	if status.Monitors[0].State != monitor.StateHealthy || status.Monitors[0].Value["block"] != float64(10) {
+		t.Fatalf("unexpected status for a: %+v", status.Monitors[0])
+	}
+	if status.Monitors[1].State != monitor.StateFailing || status.Monitors[1].LastError != "too few peers" {
+		t.Fatalf("unexpected status for b: %+v", status.Monitors[1])
+	}
+}
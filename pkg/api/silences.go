@@ -0,0 +1,121 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
+)
+
+// maxRequestSize limits the body of admin API requests.
+const maxRequestSize = 64 << 10
+
+// createSilenceRequest is the body of POST /api/v1/silences. The silence starts immediately unless start is
+// set, and ends at end or after duration, such as 2h.
+type createSilenceRequest struct {
+	Name     string               `json:"name"`
+	Comment  string               `json:"comment"`
+	Match    config.EndpointMatch `json:"match"`
+	Start    time.Time            `json:"start"`
+	End      time.Time            `json:"end"`
+	Duration string               `json:"duration"`
+}
+
+func (r createSilenceRequest) silence(now time.Time) (config.Silence, error) {
+	out := config.Silence{
+		Name:    r.Name,
+		Comment: r.Comment,
+		Match:   r.Match,
+		Start:   r.Start,
+		End:     r.End,
+	}
+	if r.Duration == "" {
+		return out, nil
+	}
+	if !r.End.IsZero() {
+		return config.Silence{}, errors.New("only one of end and duration can be set")
+	}
+	duration, err := time.ParseDuration(r.Duration)
+	if err != nil {
+		return config.Silence{}, errors.Errorf("invalid duration: %q", r.Duration)
+	}
+	if out.Start.IsZero() {
+		out.Start = now
+	}
+	out.End = out.Start.Add(duration)
+	return out, nil
+}
+
+// admin rejects requests without the admin token as a bearer token. The admin API is disabled if no token is configured.
+func (h *handler) admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.adminToken == "" {
+			h.writeError(w, http.StatusForbidden, errors.New("admin API is disabled, set admin_token to enable it"))
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			h.writeError(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *handler) listSilences(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.silences.List())
+}
+
+func (h *handler) createSilence(w http.ResponseWriter, r *http.Request) {
+	var req createSilenceRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+		return
+	}
+	s, err := req.silence(time.Now())
+	if err == nil {
+		s, err = h.silences.Add(s)
+	}
+	switch {
+	case errors.Is(err, silence.ErrExists):
+		h.writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	h.log.WithFields(logrus.Fields{
+		"silence": s.Name,
+		"start":   s.Start,
+		"end":     s.End,
+		"comment": s.Comment,
+	}).Info("silence added")
+	h.writeJSON(w, http.StatusCreated, s)
+}
+
+func (h *handler) deleteSilence(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	err := h.silences.Delete(name)
+	switch {
+	case errors.Is(err, silence.ErrNotFound):
+		h.writeError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, silence.ErrConfigured):
+		h.writeError(w, http.StatusConflict, errors.Wrap(err, "remove it from the configuration instead"))
+		return
+	case err != nil:
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.log.WithField("silence", name).Info("silence deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/silence"
+)
+
+func newSilencesHandler(adminToken string) (http.Handler, *monitor.Registry) {
+	conf := &config.Config{
+		Log:        logrus.New(),
+		AdminToken: adminToken,
+		Endpoints:  []config.Endpoint{{Name: "ep", Labels: map[string]string{"env": "prod"}}},
+	}
+	registry := monitor.NewRegistry()
+	return NewHandler(conf, registry, silence.NewManager(conf), alert.NewDeliveries()), registry
+}
+
+func request(h http.Handler, method string, path string, token string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSilencesAPI(t *testing.T) {
+	h, registry := newSilencesHandler("secret-token")
+	mon := newFakeMonitor("a")
+	registry.Register(mon)
+	mon.tracker.Record(nil, nil)
+
+	body := `{"name": "upgrade", "comment": "client upgrade", "match": {"labels": {"env": "prod"}}, "duration": "1h"}`
+	if rec := request(h, http.MethodPost, "/api/v1/silences", "", body); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rec.Code)
+	}
+	if rec := request(h, http.MethodPost, "/api/v1/silences", "wrong", body); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with a wrong token, got %d", rec.Code)
+	}
+	rec := request(h, http.MethodPost, "/api/v1/silences", "secret-token", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created config.Silence
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Name != "upgrade" || created.End.Sub(created.Start).Hours() != 1 {
+		t.Fatalf("unexpected silence: %+v", created)
+	}
+	if rec := request(h, http.MethodPost, "/api/v1/silences", "secret-token", body); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a duplicate name, got %d", rec.Code)
+	}
+	if rec := request(h, http.MethodPost, "/api/v1/silences", "secret-token", `{"duration": "1h"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a silence without match, got %d", rec.Code)
+	}
+
+	var status statusResponse
+	if err := json.Unmarshal(request(h, http.MethodGet, "/api/v1/status", "", "").Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(status.Monitors) != 1 || len(status.Monitors[0].Silences) != 1 || status.Monitors[0].Silences[0] != "upgrade" {
+		t.Fatalf("expected the monitor to show the silence, got %+v", status.Monitors)
+	}
+
+	var entries []silence.Entry
+	if err := json.Unmarshal(request(h, http.MethodGet, "/api/v1/silences", "", "").Body.Bytes(), &entries); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Active || entries[0].Source != silence.SourceAPI {
+		t.Fatalf("unexpected silences: %+v", entries)
+	}
+
+	if rec := request(h, http.MethodDelete, "/api/v1/silences/upgrade", "secret-token", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if rec := request(h, http.MethodDelete, "/api/v1/silences/upgrade", "secret-token", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 once deleted, got %d", rec.Code)
+	}
+}
+
+func TestSilencesAPIDisabledWithoutToken(t *testing.T) {
+	h, _ := newSilencesHandler("")
+	rec := request(h, http.MethodPost, "/api/v1/silences", "", `{"match": {"endpoints": ["*"]}, "duration": "1h"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 with the admin API disabled, got %d", rec.Code)
+	}
+	if rec := request(h, http.MethodGet, "/api/v1/silences", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected listing silences to stay available, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// Authenticator adds the headers, basic auth and JWT configured for an endpoint to outgoing requests.
+type Authenticator struct {
+	endpoint config.Endpoint
+}
+
+func New(endpoint config.Endpoint) *Authenticator {
+	return &Authenticator{endpoint: endpoint}
+}
+
+// Apply sets the endpoint's credentials on the request headers. It matches rpc.HTTPAuth,
+// so that it can be used for both HTTP and WebSocket connections of the go-ethereum RPC client.
+func (a *Authenticator) Apply(header http.Header) error {
+	for key, value := range a.endpoint.Headers {
+		header.Set(key, value)
+	}
+	if a.endpoint.BasicAuth.Username != "" {
+		credentials := a.endpoint.BasicAuth.Username + ":" + a.endpoint.BasicAuth.Password
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	if a.endpoint.JWTSecretFile != "" {
+		// The secret is read for every request, so that a rotated secret is picked up
+		secret, err := a.endpoint.JWTSecret()
+		if err != nil {
+			return err
+		}
+		token, err := newJWT(secret, time.Now())
+		if err != nil {
+			return err
+		}
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// Transport returns a RoundTripper applying the endpoint's credentials to every request sent through base.
+func (a *Authenticator) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{auth: a, base: base}
+}
+
+// NewClient returns an HTTP client applying the endpoint's credentials to every request.
+func NewClient(endpoint config.Endpoint, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: New(endpoint).Transport(nil),
+	}
+}
+
+type transport struct {
+	auth *Authenticator
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	if err := t.auth.Apply(req.Header); err != nil {
+		return nil, errors.Wrap(err, "failed to authenticate request")
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newJWT returns an HS256 token with the iat claim, as required by the Engine API.
+func newJWT(secret []byte, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{"iat": now.Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const testJWTSecret = "0x0102030405060708091011121314151617181920212223242526272829303132"
+
+func captureHeaders(t *testing.T, endpoint config.Endpoint) http.Header {
+	t.Helper()
+	headers := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	resp, err := NewClient(endpoint, time.Second).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return <-headers
+}
+
+func TestClient_HeadersAndBasicAuth(t *testing.T) {
+	header := captureHeaders(t, config.Endpoint{
+		Headers:   map[string]string{"X-Api-Key": "key"},
+		BasicAuth: config.BasicAuth{Username: "user", Password: "pass"},
+	})
+
+	if got := header.Get("X-Api-Key"); got != "key" {
+		t.Fatalf("expected custom header, got %q", got)
+	}
+	if got, want := header.Get("Authorization"), "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")); got != want {
+		t.Fatalf("unexpected authorization got %q want %q", got, want)
+	}
+}
+
+func TestClient_JWT(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "jwt.hex")
+	if err := os.WriteFile(secretFile, []byte(testJWTSecret+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write secret: %v", err)
+	}
+	endpoint := config.Endpoint{JWTSecretFile: secretFile}
+	header := captureHeaders(t, endpoint)
+
+	token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer ")
+	if !ok {
+		t.Fatalf("expected bearer token, got %q", header.Get("Authorization"))
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 token parts, got %d", len(parts))
+	}
+
+	secret, err := endpoint.JWTSecret()
+	if err != nil {
+		t.Fatalf("failed to read secret: %v", err)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if want := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)); parts[2] != want {
+		t.Fatalf("invalid token signature")
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !strings.Contains(string(claims), `"iat":`) {
+		t.Fatalf("expected iat claim, got %s", claims)
+	}
+}
+
+func TestClient_JWTMissingSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request without credentials must not be sent")
+	}))
+	defer srv.Close()
+
+	endpoint := config.Endpoint{JWTSecretFile: filepath.Join(t.TempDir(), "missing")}
+	if _, err := NewClient(endpoint, time.Second).Get(srv.URL); err == nil {
+		t.Fatal("expected error when the jwt secret cannot be read")
+	}
+}
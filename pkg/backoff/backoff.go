@@ -0,0 +1,50 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes exponentially increasing delays between retries, with jitter
+// so that many reconnecting clients do not retry in lockstep.
+type Backoff struct {
+	Min     time.Duration
+	Max     time.Duration
+	attempt int
+}
+
+func New(minDelay time.Duration, maxDelay time.Duration) *Backoff {
+	return &Backoff{
+		Min: minDelay,
+		Max: maxDelay,
+	}
+}
+
+// Next returns the delay to wait before the next retry, somewhere between half
+// and all of Min * 2^attempt, capped at Max.
+func (b *Backoff) Next() time.Duration {
+	delay := b.Min
+	for i := 0; i < b.attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	b.attempt++
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half+1) //nolint:gosec // jitter does not need a secure source
+}
+
+// Attempt returns the number of retries since the last reset.
+func (b *Backoff) Attempt() int {
+	return b.attempt
+}
+
+// Reset restarts the backoff from Min, after a successful attempt.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
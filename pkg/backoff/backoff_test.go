@@ -0,0 +1,40 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff_GrowsAndCaps(t *testing.T) {
+	b := New(100*time.Millisecond, time.Second)
+
+	wantMax := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, upper := range wantMax {
+		got := b.Next()
+		if got < upper/2 || got > upper {
+			t.Fatalf("attempt %d: delay %s not within [%s, %s]", i, got, upper/2, upper)
+		}
+	}
+	if b.Attempt() != len(wantMax) {
+		t.Fatalf("expected attempt=%d, got %d", len(wantMax), b.Attempt())
+	}
+}
+
+func TestBackoff_Reset(t *testing.T) {
+	b := New(100*time.Millisecond, time.Second)
+	for i := 0; i < 5; i++ {
+		b.Next()
+	}
+	b.Reset()
+
+	if got := b.Next(); got > 100*time.Millisecond {
+		t.Fatalf("expected delay to restart from min after reset, got %s", got)
+	}
+}
@@ -1,8 +1,16 @@
 package config
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -14,37 +22,585 @@ const (
 	TypeExecution          = "execution"
 	TypeConsensus          = "consensus"
 	PeerStartThreshold int = 2
+
+	DefaultListenAddress = ":8080"
+
+	blsPubKeyLength = 48
+	rootLength      = 32
+	versionLength   = 4
+	jwtSecretLength = 32
 )
 
 type Pagerduty struct {
-	Enabled    bool   `yaml:"enabled" json:"enabled"`
-	RoutingKey string `yaml:"routing_key" json:"routing_key"`
-	Service    string `yaml:"service" json:"service"`
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	RoutingKey  string   `yaml:"routing_key" json:"routing_key"`
+	Service     string   `yaml:"service" json:"service"`
+	MinSeverity Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
 }
 
 func (p Pagerduty) Empty() bool {
 	return p.RoutingKey == "" || !p.Enabled
 }
 
+const (
+	OpsgenieRegionUS = "us"
+	OpsgenieRegionEU = "eu"
+)
+
+// Opsgenie configures the Opsgenie alert channel. Unset fields of an endpoint's configuration fall back to the global one.
+type Opsgenie struct {
+	Enabled     bool                `yaml:"enabled" json:"enabled"`
+	APIKey      string              `yaml:"api_key" json:"api_key"`
+	Region      string              `yaml:"region" json:"region"` // us or eu, defaults to us
+	Responders  []OpsgenieResponder `yaml:"responders" json:"responders"`
+	Tags        []string            `yaml:"tags" json:"tags"`
+	Priorities  map[Severity]string `yaml:"priorities" json:"priorities"`     // Opsgenie priority of each severity, defaults to P1 for critical, P2 for error, P3 for warning and P5 for info
+	MinSeverity Severity            `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
+}
+
+// OpsgenieResponder is a team, user, escalation or schedule notified of an alert, identified by ID or by name.
+type OpsgenieResponder struct {
+	Type     string `yaml:"type" json:"type"`
+	ID       string `yaml:"id" json:"id,omitempty"`
+	Name     string `yaml:"name" json:"name,omitempty"`         // Teams, escalations and schedules
+	Username string `yaml:"username" json:"username,omitempty"` // Users
+}
+
+func (o Opsgenie) Empty() bool {
+	return o.APIKey == "" || !o.Enabled
+}
+
+func (o Opsgenie) validate() []error {
+	var errs []error
+	switch o.Region {
+	case "", OpsgenieRegionUS, OpsgenieRegionEU:
+	default:
+		errs = append(errs, errors.Errorf("invalid opsgenie region: %q, expected us or eu", o.Region))
+	}
+	for i, responder := range o.Responders {
+		switch responder.Type {
+		case "team", "escalation", "schedule":
+			if responder.ID == "" && responder.Name == "" {
+				errs = append(errs, errors.Errorf("opsgenie responder #%d requires id or name", i+1))
+			}
+		case "user":
+			if responder.ID == "" && responder.Username == "" {
+				errs = append(errs, errors.Errorf("opsgenie responder #%d requires id or username", i+1))
+			}
+		default:
+			errs = append(errs, errors.Errorf("invalid opsgenie responder #%d type: %q, expected team, user, escalation or schedule", i+1, responder.Type))
+		}
+	}
+	for severity, priority := range o.Priorities {
+		if severity == "" || !severity.Valid() {
+			errs = append(errs, errors.Errorf("invalid opsgenie priorities severity: %q", severity))
+		}
+		switch priority {
+		case "P1", "P2", "P3", "P4", "P5":
+		default:
+			errs = append(errs, errors.Errorf("invalid opsgenie priority for %s: %q, expected P1 to P5", severity, priority))
+		}
+	}
+	if err := validateMinSeverity("opsgenie", o.MinSeverity); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
 type Slack struct {
-	Enabled    bool   `yaml:"enabled" json:"enabled"`
-	WebhookURL string `yaml:"webhook_url" json:"webhook_url"`
-	Channel    string `yaml:"channel" json:"channel"`
-	Token      string `yaml:"token" json:"token"`
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	WebhookURL  string   `yaml:"webhook_url" json:"webhook_url"`
+	Channel     string   `yaml:"channel" json:"channel"`
+	Token       string   `yaml:"token" json:"token"`
+	MinSeverity Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
 }
 
 func (s Slack) Empty() bool {
 	return !s.Enabled || (len(s.WebhookURL) == 0 && len(s.Channel) == 0 && len(s.Token) == 0)
 }
 
+// deliverable returns true if messages can be sent, either through the webhook or the channel and token.
+func (s Slack) deliverable() bool {
+	return len(s.WebhookURL) != 0 || (len(s.Channel) != 0 && len(s.Token) != 0)
+}
+
+// Discord configures the Discord alert channel, posting to a channel webhook.
+type Discord struct {
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	WebhookURL  string   `yaml:"webhook_url" json:"webhook_url"`
+	MinSeverity Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
+}
+
+// Or returns the settings with every unset field taken from fallback.
+func (d Discord) Or(fallback Discord) Discord {
+	if d.WebhookURL == "" {
+		d.WebhookURL = fallback.WebhookURL
+	}
+	if d.MinSeverity == "" {
+		d.MinSeverity = fallback.MinSeverity
+	}
+	return d
+}
+
+func (d Discord) validate() []error {
+	var errs []error
+	if err := validateURL("discord webhook_url", d.WebhookURL); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateMinSeverity("discord", d.MinSeverity); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Telegram configures the Telegram alert channel, sending messages to a chat through a bot.
+type Telegram struct {
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	BotToken    string   `yaml:"bot_token" json:"bot_token"`
+	ChatID      string   `yaml:"chat_id" json:"chat_id"`           // Numeric chat ID, or @channelusername for public channels
+	MinSeverity Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
+}
+
+// Or returns the settings with every unset field taken from fallback.
+func (t Telegram) Or(fallback Telegram) Telegram {
+	if t.BotToken == "" {
+		t.BotToken = fallback.BotToken
+	}
+	if t.ChatID == "" {
+		t.ChatID = fallback.ChatID
+	}
+	if t.MinSeverity == "" {
+		t.MinSeverity = fallback.MinSeverity
+	}
+	return t
+}
+
+// deliverable returns true if the bot token and chat are set.
+func (t Telegram) deliverable() bool {
+	return t.BotToken != "" && t.ChatID != ""
+}
+
+func (t Telegram) validate() []error {
+	var errs []error
+	if err := validateMinSeverity("telegram", t.MinSeverity); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Teams configures the Microsoft Teams alert channel, posting adaptive cards to an incoming webhook or workflow.
+type Teams struct {
+	Enabled     bool     `yaml:"enabled" json:"enabled"`
+	WebhookURL  string   `yaml:"webhook_url" json:"webhook_url"`
+	MinSeverity Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
+}
+
+// Or returns the settings with every unset field taken from fallback.
+func (t Teams) Or(fallback Teams) Teams {
+	if t.WebhookURL == "" {
+		t.WebhookURL = fallback.WebhookURL
+	}
+	if t.MinSeverity == "" {
+		t.MinSeverity = fallback.MinSeverity
+	}
+	return t
+}
+
+func (t Teams) validate() []error {
+	var errs []error
+	if err := validateURL("teams webhook_url", t.WebhookURL); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateMinSeverity("teams", t.MinSeverity); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// validateURL returns an error if value is set but not an absolute URL.
+func validateURL(field string, value string) error {
+	if value == "" {
+		return nil
+	}
+	if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.Errorf("invalid %s: %s", field, value)
+	}
+	return nil
+}
+
+// Webhook configures a generic HTTP alert channel. Body is a text/template rendered with the
+// alert message, the "json" function encodes a value as JSON.
+type Webhook struct {
+	Enabled bool              `yaml:"enabled" json:"enabled"`
+	URL     string            `yaml:"url" json:"url"`
+	Method  string            `yaml:"method" json:"method"` // Defaults to POST
+	Headers map[string]string `yaml:"headers" json:"headers"`
+	Body    string            `yaml:"body" json:"body"` // Defaults to the alert message encoded as JSON
+
+	MinSeverity Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
+}
+
+// Or returns the settings with every unset field taken from fallback.
+func (w Webhook) Or(fallback Webhook) Webhook {
+	if w.URL == "" {
+		w.URL = fallback.URL
+	}
+	if w.Method == "" {
+		w.Method = fallback.Method
+	}
+	if w.Headers == nil {
+		w.Headers = fallback.Headers
+	}
+	if w.Body == "" {
+		w.Body = fallback.Body
+	}
+	if w.MinSeverity == "" {
+		w.MinSeverity = fallback.MinSeverity
+	}
+	return w
+}
+
+func (w Webhook) Empty() bool {
+	return !w.Enabled || len(w.URL) == 0
+}
+
+// Template parses the body template of the webhook.
+func (w Webhook) Template() (*template.Template, error) {
+	return template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(w.Body)
+}
+
+func (w Webhook) validate() []error {
+	var errs []error
+	if len(w.URL) != 0 {
+		if u, err := url.Parse(w.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, errors.Errorf("invalid webhook url: %s", w.URL))
+		}
+	}
+	if _, err := w.Template(); err != nil {
+		errs = append(errs, errors.Wrap(err, "invalid webhook body template"))
+	}
+	if err := validateMinSeverity("webhook", w.MinSeverity); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+const (
+	EmailTLSStartTLS = "starttls"
+	EmailTLSImplicit = "tls"
+	EmailTLSNone     = "none"
+)
+
+// Email configures the SMTP alert channel. Unset fields of an endpoint's configuration fall back to the global one.
+type Email struct {
+	Enabled  bool          `yaml:"enabled" json:"enabled"`
+	Host     string        `yaml:"host" json:"host"`
+	Port     int           `yaml:"port" json:"port"` // Defaults to 465 with implicit TLS, and 587 otherwise
+	Username string        `yaml:"username" json:"username"`
+	Password string        `yaml:"password" json:"password"`
+	TLS      string        `yaml:"tls" json:"tls"` // starttls, tls or none, defaults to starttls
+	From     string        `yaml:"from" json:"from"`
+	To       []string      `yaml:"to" json:"to"`
+	Digest   time.Duration `yaml:"digest" json:"digest"` // Alerts raised within this window are sent in a single email, 0 sends every alert immediately
+
+	MinSeverity Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not sent, empty sends every alert
+}
+
+// Or returns the settings with every unset field taken from fallback.
+func (e Email) Or(fallback Email) Email {
+	if e.Host == "" {
+		e.Host = fallback.Host
+	}
+	if e.Port == 0 {
+		e.Port = fallback.Port
+	}
+	if e.Username == "" {
+		e.Username = fallback.Username
+	}
+	if e.Password == "" {
+		e.Password = fallback.Password
+	}
+	if e.TLS == "" {
+		e.TLS = fallback.TLS
+	}
+	if e.From == "" {
+		e.From = fallback.From
+	}
+	if e.To == nil {
+		e.To = fallback.To
+	}
+	if e.Digest == 0 {
+		e.Digest = fallback.Digest
+	}
+	if e.MinSeverity == "" {
+		e.MinSeverity = fallback.MinSeverity
+	}
+	return e
+}
+
+func (e Email) Empty() bool {
+	return !e.Enabled || e.Host == ""
+}
+
+// deliverable returns true if the server, sender and recipients are set.
+func (e Email) deliverable() bool {
+	return e.Host != "" && e.From != "" && len(e.To) > 0
+}
+
+func (e Email) validate() []error {
+	var errs []error
+	switch e.TLS {
+	case "", EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone:
+	default:
+		errs = append(errs, errors.Errorf("invalid email tls: %q, expected starttls, tls or none", e.TLS))
+	}
+	if e.Port < 0 || e.Port > 65535 {
+		errs = append(errs, errors.Errorf("invalid email port: %d", e.Port))
+	}
+	if e.From != "" {
+		if _, err := mail.ParseAddress(e.From); err != nil {
+			errs = append(errs, errors.Errorf("invalid email from address: %q", e.From))
+		}
+	}
+	for _, to := range e.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			errs = append(errs, errors.Errorf("invalid email to address: %q", to))
+		}
+	}
+	if e.Digest < 0 {
+		errs = append(errs, errors.New("email digest must not be negative"))
+	}
+	if err := validateMinSeverity("email", e.MinSeverity); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+const (
+	defaultDeliveryQueueSize    = 100
+	defaultDeliveryMaxAttempts  = 5
+	defaultDeliveryMinBackoff   = time.Second
+	defaultDeliveryMaxBackoff   = time.Minute
+	defaultDeliveryDrainTimeout = 10 * time.Second
+)
+
+// Delivery configures how alerts are delivered to their channels. Every channel of an endpoint has its own queue,
+// so that a slow channel does not hold back the monitors or the other channels.
+type Delivery struct {
+	QueueSize      int           `yaml:"queue_size" json:"queue_size"`             // Alerts waiting for delivery on a channel, defaults to 100
+	MaxAttempts    int           `yaml:"max_attempts" json:"max_attempts"`         // Attempts to deliver an alert before giving up, defaults to 5
+	MinBackoff     time.Duration `yaml:"min_backoff" json:"min_backoff"`           // Delay before the first retry, doubled on each retry, defaults to 1s
+	MaxBackoff     time.Duration `yaml:"max_backoff" json:"max_backoff"`           // Longest delay between retries, defaults to 1m
+	DeadLetterFile string        `yaml:"dead_letter_file" json:"dead_letter_file"` // File the alerts that could not be delivered are appended to as JSON lines, they are only logged if empty
+	DrainTimeout   time.Duration `yaml:"drain_timeout" json:"drain_timeout"`       // How long the queued alerts are still delivered on a reload or shutdown, defaults to 10s
+}
+
+// OrDefault returns the settings with every unset field set to its default.
+func (d Delivery) OrDefault() Delivery {
+	if d.QueueSize == 0 {
+		d.QueueSize = defaultDeliveryQueueSize
+	}
+	if d.MaxAttempts == 0 {
+		d.MaxAttempts = defaultDeliveryMaxAttempts
+	}
+	if d.MinBackoff == 0 {
+		d.MinBackoff = defaultDeliveryMinBackoff
+	}
+	if d.MaxBackoff == 0 {
+		d.MaxBackoff = max(defaultDeliveryMaxBackoff, d.MinBackoff)
+	}
+	if d.DrainTimeout == 0 {
+		d.DrainTimeout = defaultDeliveryDrainTimeout
+	}
+	return d
+}
+
+func (d Delivery) validate() []error {
+	var errs []error
+	if d.QueueSize < 0 {
+		errs = append(errs, errors.New("delivery queue_size must not be negative"))
+	}
+	if d.MaxAttempts < 0 {
+		errs = append(errs, errors.New("delivery max_attempts must not be negative"))
+	}
+	if d.MinBackoff < 0 || d.MaxBackoff < 0 {
+		errs = append(errs, errors.New("delivery min_backoff and max_backoff must not be negative"))
+	} else if d.MaxBackoff > 0 && d.MaxBackoff < d.MinBackoff {
+		errs = append(errs, errors.New("delivery max_backoff must not be less than min_backoff"))
+	}
+	if d.DrainTimeout < 0 {
+		errs = append(errs, errors.New("delivery drain_timeout must not be negative"))
+	}
+	return errs
+}
+
+const (
+	defaultHeartbeatInterval    = time.Minute
+	defaultHeartbeatMaxCheckAge = 10 * time.Minute
+)
+
+// Heartbeat configures a dead man's switch: URL is requested every interval while every monitor is making
+// progress, so that an external watchdog, such as healthchecks.io or a PagerDuty heartbeat, alerts once the
+// requests stop because the process is down or a monitor is stuck.
+type Heartbeat struct {
+	URL         string        `yaml:"url" json:"url"`                     // Heartbeats are disabled if empty
+	Interval    time.Duration `yaml:"interval" json:"interval"`           // Defaults to 1m
+	MaxCheckAge time.Duration `yaml:"max_check_age" json:"max_check_age"` // A monitor whose last check is older is stuck, defaults to 10m
+}
+
+// Enabled returns true if a heartbeat URL is configured.
+func (h Heartbeat) Enabled() bool {
+	return h.URL != ""
+}
+
+// OrDefault returns the settings with every unset field set to its default.
+func (h Heartbeat) OrDefault() Heartbeat {
+	if h.Interval == 0 {
+		h.Interval = defaultHeartbeatInterval
+	}
+	if h.MaxCheckAge == 0 {
+		h.MaxCheckAge = defaultHeartbeatMaxCheckAge
+	}
+	return h
+}
+
+func (h Heartbeat) validate(endpoints []Endpoint) []error {
+	var errs []error
+	if err := validateURL("heartbeat url", h.URL); err != nil {
+		errs = append(errs, err)
+	}
+	if h.Interval < 0 || h.MaxCheckAge < 0 {
+		errs = append(errs, errors.New("heartbeat interval and max_check_age must not be negative"))
+	}
+	if !h.Enabled() {
+		return errs
+	}
+	// Monitors check once per poll_duration at most, so they would always be reported as stuck
+	maxCheckAge := h.OrDefault().MaxCheckAge
+	for _, endpoint := range endpoints {
+		if endpoint.PollDuration >= maxCheckAge {
+			errs = append(errs, errors.Errorf("heartbeat max_check_age %s must be longer than the poll_duration %s of endpoint %q", maxCheckAge, endpoint.PollDuration, endpoint.Name))
+		}
+	}
+	return errs
+}
+
+// HeadLag configures how far an endpoint may fall behind the reference endpoints of its network.
+type HeadLag struct {
+	MaxLag      uint64        `yaml:"max_lag" json:"max_lag"`           // Blocks for execution endpoints, slots for consensus endpoints
+	GracePeriod time.Duration `yaml:"grace_period" json:"grace_period"` // How long the endpoint may stay behind before alerting
+}
+
+// BasicAuth configures HTTP basic authentication for an endpoint.
+type BasicAuth struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// PeerCount configures tiered peer count thresholds, alerting with warning and critical severity respectively.
+type PeerCount struct {
+	Warning      int `yaml:"warning" json:"warning"`             // Alert with warning severity below this many peers
+	Critical     int `yaml:"critical" json:"critical"`           // Alert with critical severity below this many peers
+	RecoverAbove int `yaml:"recover_above" json:"recover_above"` // An ongoing alert resolves once the peer count is above this, defaults to just below the highest threshold
+	Samples      int `yaml:"samples" json:"samples"`             // Consecutive samples below a threshold before alerting, defaults to 1
+}
+
+// Enabled returns true if a warning or critical threshold is configured.
+func (p PeerCount) Enabled() bool {
+	return p.Warning > 0 || p.Critical > 0
+}
+
+// Genesis identifies the chain a consensus endpoint is expected to follow, as reported by /eth/v1/beacon/genesis.
+type Genesis struct {
+	ValidatorsRoot string `yaml:"validators_root" json:"validators_root"` // 0x-prefixed genesis validators root
+	ForkVersion    string `yaml:"fork_version" json:"fork_version"`       // 0x-prefixed genesis fork version
+}
+
+// Enabled returns true if the genesis of the endpoint is checked.
+func (g Genesis) Enabled() bool {
+	return g.ValidatorsRoot != "" || g.ForkVersion != ""
+}
+
+func (g Genesis) validate() []error {
+	var errs []error
+	if g.ValidatorsRoot != "" && !isHexBytes(g.ValidatorsRoot, rootLength) {
+		errs = append(errs, errors.Errorf("invalid genesis.validators_root: %q, expected 0x-prefixed %d byte hex", g.ValidatorsRoot, rootLength))
+	}
+	if g.ForkVersion != "" && !isHexBytes(g.ForkVersion, versionLength) {
+		errs = append(errs, errors.Errorf("invalid genesis.fork_version: %q, expected 0x-prefixed %d byte hex", g.ForkVersion, versionLength))
+	}
+	return errs
+}
+
+func isHexBytes(s string, length int) bool {
+	data, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	return strings.HasPrefix(s, "0x") && err == nil && len(data) == length
+}
+
+// Reorg configures when an endpoint alerts on chain reorganizations. Shallower reorgs are only logged and counted.
+type Reorg struct {
+	MaxDepth uint64 `yaml:"max_depth" json:"max_depth"` // Blocks, or slots on consensus endpoints, a reorg may replace without alerting
+}
+
+// Enabled returns true if reorgs of the endpoint are monitored.
+func (r Reorg) Enabled() bool {
+	return r.MaxDepth > 0
+}
+
+// Finality configures when a consensus endpoint alerts on the chain not finalizing.
+type Finality struct {
+	MaxStalledEpochs uint64 `yaml:"max_stalled_epochs" json:"max_stalled_epochs"` // Epochs the finalized checkpoint may be behind the current epoch, 2 on a healthy chain
+	MaxJustifiedGap  uint64 `yaml:"max_justified_gap" json:"max_justified_gap"`   // Epochs the justified checkpoint may be ahead of the finalized checkpoint
+}
+
+// Enabled returns true if any finality check is configured.
+func (f Finality) Enabled() bool {
+	return f.MaxStalledEpochs > 0 || f.MaxJustifiedGap > 0
+}
+
+// Validators configures monitoring of validators attached to a consensus endpoint, checked once per epoch.
+type Validators struct {
+	IDs                   []string `yaml:"ids" json:"ids"`                                         // Validator indices or 0x-prefixed public keys
+	MaxMissedAttestations int      `yaml:"max_missed_attestations" json:"max_missed_attestations"` // Validators that may miss attesting in an epoch before alerting
+	MaxMissedProposals    int      `yaml:"max_missed_proposals" json:"max_missed_proposals"`       // Block proposals that may be missed in an epoch before alerting
+	MaxBalanceDecrease    uint64   `yaml:"max_balance_decrease" json:"max_balance_decrease"`       // Gwei a validator may lose in an epoch before alerting, 0 disables the check
+	AlertOnInactive       bool     `yaml:"alert_on_inactive" json:"alert_on_inactive"`             // Alert on validators that are pending, exiting or exited
+}
+
+// Enabled returns true if any validator is configured.
+func (v Validators) Enabled() bool {
+	return len(v.IDs) > 0
+}
+
 type Config struct {
-	Endpoints  []Endpoint    `yaml:"endpoints" json:"endpoints"`
-	RPCTimeout time.Duration `yaml:"rpc_timeout" json:"rpc_timeout"`
-	Pagerduty  Pagerduty     `yaml:"pagerduty" json:"pagerduty"`
-	Slack      Slack         `yaml:"slack" json:"slack"`
-	Verbosity  string        `yaml:"verbosity" json:"verbosity"`
+	Endpoints     []Endpoint    `yaml:"endpoints" json:"endpoints"`
+	RPCTimeout    time.Duration `yaml:"rpc_timeout" json:"rpc_timeout"`
+	Pagerduty     Pagerduty     `yaml:"pagerduty" json:"pagerduty"`
+	Opsgenie      Opsgenie      `yaml:"opsgenie" json:"opsgenie"`
+	Email         Email         `yaml:"email" json:"email"`
+	Discord       Discord       `yaml:"discord" json:"discord"`
+	Telegram      Telegram      `yaml:"telegram" json:"telegram"`
+	Teams         Teams         `yaml:"teams" json:"teams"`
+	Slack         Slack         `yaml:"slack" json:"slack"`
+	Webhook       Webhook       `yaml:"webhook" json:"webhook"`
+	Receivers     []Receiver    `yaml:"receivers" json:"receivers"`
+	Routes        []Route       `yaml:"routes" json:"routes"` // Replace the global and per-endpoint alert channels when set
+	Silences      []Silence     `yaml:"silences" json:"silences"`
+	Delivery      Delivery      `yaml:"delivery" json:"delivery"`
+	Heartbeat     Heartbeat     `yaml:"heartbeat" json:"heartbeat"`
+	Verbosity     string        `yaml:"verbosity" json:"verbosity"`
+	ListenAddress string        `yaml:"listen_address" json:"listen_address"` // Address of the HTTP server exposing metrics, defaults to :8080
+	AdminToken    string        `yaml:"admin_token" json:"admin_token"`       // Bearer token of the admin API managing silences, which is disabled if empty
 
 	Log logrus.Ext1FieldLogger `yaml:"-" json:"-"` // Log field is not serialized to YAML, used for logging
+
+	secrets []string // Values resolved from secret references, redacted from logs and status output
+	offline bool     // Loaded by ValidateConfig, secret references and files are not read
 }
 
 func LoadConfigFromFile(file string) (*Config, error) {
@@ -56,7 +612,18 @@ func LoadConfigFromFile(file string) (*Config, error) {
 }
 
 func LoadConfig(data []byte) (*Config, error) {
-	conf := &Config{}
+	return loadConfig(data, false)
+}
+
+// ValidateConfig parses and validates the configuration like LoadConfig, without reading the secrets and files
+// it references, so that it can be checked where they are not available. Secret references only need to be
+// well-formed, and the format of the values they are part of is not checked.
+func ValidateConfig(data []byte) (*Config, error) {
+	return loadConfig(data, true)
+}
+
+func loadConfig(data []byte, offline bool) (*Config, error) {
+	conf := &Config{offline: offline}
 	err := yaml.Unmarshal(data, conf)
 	if err != nil {
 		err = json.Unmarshal(data, conf)
@@ -65,7 +632,12 @@ func LoadConfig(data []byte) (*Config, error) {
 		}
 		return nil, err
 	}
+	if err := conf.resolveSecrets(); err != nil {
+		return nil, errors.Newf("invalid configuration:\n%w", err)
+	}
+
 	logger := logrus.New()
+	logger.AddHook(redactHook{conf: conf})
 	lvl, err := logrus.ParseLevel(conf.Verbosity)
 	if err != nil {
 		logger.SetLevel(logrus.InfoLevel)
@@ -77,34 +649,339 @@ func LoadConfig(data []byte) (*Config, error) {
 	if conf.RPCTimeout == 0 {
 		conf.RPCTimeout = 10 * time.Second
 	}
+	if conf.ListenAddress == "" {
+		conf.ListenAddress = DefaultListenAddress
+	}
+	if err := conf.Validate(); err != nil {
+		// Problems may quote values resolved from secret references
+		return nil, errors.Newf("invalid configuration:\n%s", conf.Redact(err.Error()))
+	}
 	return conf, nil
 }
 
+// Validate returns all structural problems of the configuration joined into a single error,
+// one problem per line.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Verbosity != "" {
+		if _, err := logrus.ParseLevel(c.Verbosity); err != nil {
+			errs = append(errs, errors.Errorf("invalid verbosity: %q", c.Verbosity))
+		}
+	}
+	if c.RPCTimeout < 0 {
+		errs = append(errs, errors.New("rpc_timeout must not be negative"))
+	}
+	if c.Pagerduty.Enabled && c.Pagerduty.RoutingKey == "" {
+		errs = append(errs, errors.New("pagerduty is enabled but routing_key is empty"))
+	}
+	if c.Opsgenie.Enabled && c.Opsgenie.APIKey == "" {
+		errs = append(errs, errors.New("opsgenie is enabled but api_key is empty"))
+	}
+	errs = append(errs, c.Opsgenie.validate()...)
+	if c.Email.Enabled && !c.Email.deliverable() {
+		errs = append(errs, errors.New("email is enabled but host, from or to is empty"))
+	}
+	errs = append(errs, c.Email.validate()...)
+	if c.Discord.Enabled && c.Discord.WebhookURL == "" {
+		errs = append(errs, errors.New("discord is enabled but webhook_url is empty"))
+	}
+	errs = append(errs, c.Discord.validate()...)
+	if c.Telegram.Enabled && !c.Telegram.deliverable() {
+		errs = append(errs, errors.New("telegram is enabled but bot_token or chat_id is empty"))
+	}
+	errs = append(errs, c.Telegram.validate()...)
+	if c.Teams.Enabled && c.Teams.WebhookURL == "" {
+		errs = append(errs, errors.New("teams is enabled but webhook_url is empty"))
+	}
+	errs = append(errs, c.Teams.validate()...)
+	if c.Slack.Enabled && !c.Slack.deliverable() {
+		errs = append(errs, errors.New("slack is enabled but neither webhook_url nor channel and token are set"))
+	}
+	if c.Webhook.Enabled && len(c.Webhook.URL) == 0 {
+		errs = append(errs, errors.New("webhook is enabled but url is empty"))
+	}
+	errs = append(errs, c.Webhook.validate()...)
+	for _, err := range []error{
+		validateMinSeverity("pagerduty", c.Pagerduty.MinSeverity),
+		validateMinSeverity("slack", c.Slack.MinSeverity),
+	} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, c.Delivery.validate()...)
+	errs = append(errs, c.Heartbeat.validate(c.Endpoints)...)
+	errs = append(errs, c.validateRoutes()...)
+	errs = append(errs, c.validateSilences()...)
+	if len(c.Endpoints) == 0 {
+		errs = append(errs, errors.New("at least one endpoint is required"))
+	}
+
+	names := map[string]bool{}
+	for i, endpoint := range c.Endpoints {
+		prefix := fmt.Sprintf("endpoint %q", endpoint.Name)
+		if endpoint.Name == "" {
+			prefix = fmt.Sprintf("endpoint #%d", i+1)
+		} else if names[endpoint.Name] {
+			errs = append(errs, errors.Newf("%s: duplicate endpoint name", prefix))
+		}
+		names[endpoint.Name] = true
+
+		endpointErrs := endpoint.validate()
+		if !c.offline {
+			endpointErrs = append(endpointErrs, endpoint.validateFiles()...)
+		}
+		if endpoint.Pagerduty.Enabled && endpoint.Pagerduty.RoutingKey == "" && c.Pagerduty.RoutingKey == "" {
+			endpointErrs = append(endpointErrs, errors.New("pagerduty is enabled but no routing_key is set on the endpoint or globally"))
+		}
+		if endpoint.Opsgenie.Enabled && endpoint.Opsgenie.APIKey == "" && c.Opsgenie.APIKey == "" {
+			endpointErrs = append(endpointErrs, errors.New("opsgenie is enabled but no api_key is set on the endpoint or globally"))
+		}
+		endpointErrs = append(endpointErrs, endpoint.Opsgenie.validate()...)
+		if endpoint.Email.Enabled && !endpoint.Email.Or(c.Email).deliverable() {
+			endpointErrs = append(endpointErrs, errors.New("email is enabled but no host, from or to is set on the endpoint or globally"))
+		}
+		endpointErrs = append(endpointErrs, endpoint.Email.validate()...)
+		if endpoint.Discord.Enabled && endpoint.Discord.Or(c.Discord).WebhookURL == "" {
+			endpointErrs = append(endpointErrs, errors.New("discord is enabled but no webhook_url is set on the endpoint or globally"))
+		}
+		endpointErrs = append(endpointErrs, endpoint.Discord.validate()...)
+		if endpoint.Telegram.Enabled && !endpoint.Telegram.Or(c.Telegram).deliverable() {
+			endpointErrs = append(endpointErrs, errors.New("telegram is enabled but no bot_token or chat_id is set on the endpoint or globally"))
+		}
+		endpointErrs = append(endpointErrs, endpoint.Telegram.validate()...)
+		if endpoint.Teams.Enabled && endpoint.Teams.Or(c.Teams).WebhookURL == "" {
+			endpointErrs = append(endpointErrs, errors.New("teams is enabled but no webhook_url is set on the endpoint or globally"))
+		}
+		endpointErrs = append(endpointErrs, endpoint.Teams.validate()...)
+		if endpoint.Slack.Enabled && !endpoint.Slack.deliverable() && !c.Slack.deliverable() {
+			endpointErrs = append(endpointErrs, errors.New("slack is enabled but no webhook_url or channel and token are set on the endpoint or globally"))
+		}
+		if endpoint.Webhook.Enabled && len(endpoint.Webhook.Or(c.Webhook).URL) == 0 {
+			endpointErrs = append(endpointErrs, errors.New("webhook is enabled but no url is set on the endpoint or globally"))
+		}
+		endpointErrs = append(endpointErrs, endpoint.Webhook.validate()...)
+		for _, err := range []error{
+			validateMinSeverity("pagerduty", endpoint.Pagerduty.MinSeverity),
+			validateMinSeverity("slack", endpoint.Slack.MinSeverity),
+		} {
+			if err != nil {
+				endpointErrs = append(endpointErrs, err)
+			}
+		}
+		for _, err := range endpointErrs {
+			errs = append(errs, errors.Wrap(err, prefix))
+		}
+	}
+
+	if c.offline {
+		// The placeholders of secret references are not in the format of the values they replace
+		errs = slices.DeleteFunc(errs, func(err error) bool {
+			return strings.Contains(err.Error(), secretPlaceholder)
+		})
+	}
+	return errors.Join(errs...)
+}
+
 type Endpoint struct {
-	Name                string        `yaml:"name" json:"name"`
-	URL                 string        `yaml:"url" json:"url"`
-	Type                string        `yaml:"type" json:"type"`
-	NewBlockMaxDuration time.Duration `yaml:"new_block_max_duration" json:"new_block_max_duration"`
-	MinPeers            int           `yaml:"min_peers" json:"min_peers"`
-	Pagerduty           Pagerduty     `yaml:"pagerduty" json:"pagerduty"`
-	Slack               Slack         `yaml:"slack" json:"slack"`
-	PollDuration        time.Duration `yaml:"poll_duration" json:"poll_duration"`
+	Name                string            `yaml:"name" json:"name"`
+	URL                 string            `yaml:"url" json:"url"`
+	Type                string            `yaml:"type" json:"type"`
+	Labels              map[string]string `yaml:"labels" json:"labels"`   // Matched by alert routes
+	Headers             map[string]string `yaml:"headers" json:"headers"` // Sent with every request to the endpoint
+	BasicAuth           BasicAuth         `yaml:"basic_auth" json:"basic_auth"`
+	JWTSecretFile       string            `yaml:"jwt_secret_file" json:"jwt_secret_file"` // Hex encoded Engine API secret, a token is signed for every request
+	NewBlockMaxDuration time.Duration     `yaml:"new_block_max_duration" json:"new_block_max_duration"`
+	MinPeers            int               `yaml:"min_peers" json:"min_peers"`
+	Pagerduty           Pagerduty         `yaml:"pagerduty" json:"pagerduty"`
+	Opsgenie            Opsgenie          `yaml:"opsgenie" json:"opsgenie"`
+	Email               Email             `yaml:"email" json:"email"`
+	Discord             Discord           `yaml:"discord" json:"discord"`
+	Telegram            Telegram          `yaml:"telegram" json:"telegram"`
+	Teams               Teams             `yaml:"teams" json:"teams"`
+	Slack               Slack             `yaml:"slack" json:"slack"`
+	Webhook             Webhook           `yaml:"webhook" json:"webhook"` // Unset fields are taken from the global webhook
+	PollDuration        time.Duration     `yaml:"poll_duration" json:"poll_duration"`
+	Subscribe           bool              `yaml:"subscribe" json:"subscribe"`                       // Subscribe to newHeads instead of polling the block number, requires a ws:// or wss:// execution endpoint
+	StreamGracePeriod   time.Duration     `yaml:"stream_grace_period" json:"stream_grace_period"`   // How long a consensus event stream may be disconnected before alerting, defaults to NewBlockMaxDuration
+	MaxSyncingDuration  time.Duration     `yaml:"max_syncing_duration" json:"max_syncing_duration"` // How long an execution node may report syncing before alerting
+	MaxSyncDistance     uint64            `yaml:"max_sync_distance" json:"max_sync_distance"`       // How many blocks a syncing execution node may be behind before alerting
+	Network             string            `yaml:"network" json:"network"`                           // Endpoints of the same type and network are compared against each other
+	Reference           bool              `yaml:"reference" json:"reference"`                       // Reference endpoints provide the head other endpoints in the network are compared to
+	HeadLag             HeadLag           `yaml:"head_lag" json:"head_lag"`
+	PeerCount           PeerCount         `yaml:"peer_count" json:"peer_count"`
+	ChainID             uint64            `yaml:"chain_id" json:"chain_id"` // Expected eth_chainId of an execution endpoint
+	Genesis             Genesis           `yaml:"genesis" json:"genesis"`   // Expected genesis of a consensus endpoint
+	Reorg               Reorg             `yaml:"reorg" json:"reorg"`
+	Finality            Finality          `yaml:"finality" json:"finality"`
+	Validators          Validators        `yaml:"validators" json:"validators"`
+	Severity            Severities        `yaml:"severity" json:"severity"`
 }
 
+// Validate returns all structural problems of the endpoint. Alert channels are checked by
+// Config.Validate, as they may fall back to the global configuration.
 func (e Endpoint) Validate() error {
+	return errors.Join(append(e.validate(), e.validateFiles()...)...)
+}
+
+// JWTSecret reads the hex encoded JWT secret of the endpoint from JWTSecretFile.
+func (e Endpoint) JWTSecret() ([]byte, error) {
+	data, err := os.ReadFile(e.JWTSecretFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read jwt secret")
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil || len(secret) != jwtSecretLength {
+		return nil, errors.Errorf("jwt secret in %s must be %d hex encoded bytes", e.JWTSecretFile, jwtSecretLength)
+	}
+	return secret, nil
+}
+
+// ChainCheckEnabled returns true if the endpoint declares the chain it is expected to follow.
+func (e Endpoint) ChainCheckEnabled() bool {
+	return e.ChainID > 0 || e.Genesis.Enabled()
+}
+
+// PeerCountEnabled returns true if the peer count of the endpoint is monitored.
+func (e Endpoint) PeerCountEnabled() bool {
+	return e.MinPeers > 0 || e.PeerCount.Enabled()
+}
+
+// validateFiles returns the problems of the files the endpoint reads.
+func (e Endpoint) validateFiles() []error {
+	var errs []error
+	if e.JWTSecretFile != "" {
+		if _, err := e.JWTSecret(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (e Endpoint) validate() []error {
+	var errs []error
 	if len(e.Name) == 0 {
-		return errors.New("endpoint name is required")
+		errs = append(errs, errors.New("endpoint name is required"))
 	}
 	if len(e.URL) == 0 {
-		return errors.New("endpoint URL is required")
+		errs = append(errs, errors.New("endpoint URL is required"))
+	} else if u, err := url.Parse(e.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, errors.Errorf("invalid endpoint URL: %s", e.URL))
 	}
 
 	switch e.Type {
 	case TypeExecution:
+		if e.NewBlockMaxDuration <= 0 {
+			errs = append(errs, errors.New("new_block_max_duration must be greater than zero"))
+		}
+		if e.Finality.Enabled() {
+			errs = append(errs, errors.New("finality is only supported on consensus endpoints"))
+		}
+		if e.Validators.Enabled() {
+			errs = append(errs, errors.New("validators are only supported on consensus endpoints"))
+		}
+		if e.Subscribe && !strings.HasPrefix(e.URL, "ws://") && !strings.HasPrefix(e.URL, "wss://") {
+			errs = append(errs, errors.New("subscribe requires a ws:// or wss:// endpoint URL"))
+		}
+		if e.Genesis.Enabled() {
+			errs = append(errs, errors.New("genesis is only supported on consensus endpoints, use chain_id for execution endpoints"))
+		}
 	case TypeConsensus:
+		if e.NewBlockMaxDuration < 0 {
+			errs = append(errs, errors.New("new_block_max_duration must not be negative"))
+		}
+		if e.MaxSyncingDuration > 0 || e.MaxSyncDistance > 0 {
+			errs = append(errs, errors.New("max_syncing_duration and max_sync_distance are only supported on execution endpoints"))
+		}
+		if e.Subscribe {
+			errs = append(errs, errors.New("subscribe is only supported on execution endpoints, consensus endpoints always use the events stream"))
+		}
+		if e.ChainID > 0 {
+			errs = append(errs, errors.New("chain_id is only supported on execution endpoints, use genesis for consensus endpoints"))
+		}
+		errs = append(errs, e.Genesis.validate()...)
+		if e.Finality.MaxStalledEpochs == 1 {
+			errs = append(errs, errors.New("finality.max_stalled_epochs must be at least 2, the finalized checkpoint is 2 epochs behind the current epoch on a healthy chain"))
+		}
 	default:
-		return errors.Errorf("invalid endpoint type: %s", e.Type)
+		errs = append(errs, errors.Errorf("invalid endpoint type: %q", e.Type))
 	}
 
-	return nil
+	if e.PollDuration <= 0 {
+		errs = append(errs, errors.New("poll_duration must be greater than zero"))
+	}
+	if e.MinPeers < 0 {
+		errs = append(errs, errors.New("min_peers must not be negative"))
+	}
+	errs = append(errs, e.PeerCount.validate(e.MinPeers)...)
+	if e.JWTSecretFile != "" {
+		if e.BasicAuth.Username != "" {
+			errs = append(errs, errors.New("jwt_secret_file and basic_auth both set the Authorization header, only one may be used"))
+		}
+	}
+	for key := range e.Headers {
+		if strings.EqualFold(key, "Authorization") && (e.JWTSecretFile != "" || e.BasicAuth.Username != "") {
+			errs = append(errs, errors.Errorf("headers set %s, which jwt_secret_file and basic_auth replace, only one may be used", key))
+		}
+	}
+	if e.BasicAuth.Username == "" && e.BasicAuth.Password != "" {
+		errs = append(errs, errors.New("basic_auth.username is required with basic_auth.password"))
+	}
+	if e.StreamGracePeriod < 0 {
+		errs = append(errs, errors.New("stream_grace_period must not be negative"))
+	}
+	if e.MaxSyncingDuration < 0 {
+		errs = append(errs, errors.New("max_syncing_duration must not be negative"))
+	}
+	if e.HeadLag.GracePeriod < 0 {
+		errs = append(errs, errors.New("head_lag.grace_period must not be negative"))
+	}
+	if e.Network == "" && (e.Reference || e.HeadLag.MaxLag > 0) {
+		errs = append(errs, errors.New("network is required to compare head lag"))
+	}
+
+	errs = append(errs, e.Severity.validate()...)
+	return append(errs, e.Validators.validate()...)
+}
+
+func (p PeerCount) validate(minPeers int) []error {
+	var errs []error
+	if p.Warning < 0 || p.Critical < 0 || p.RecoverAbove < 0 || p.Samples < 0 {
+		errs = append(errs, errors.New("peer_count thresholds must not be negative"))
+	}
+	if p.Warning > 0 && p.Critical > p.Warning {
+		errs = append(errs, errors.New("peer_count.critical must not be above peer_count.warning"))
+	}
+	if p.RecoverAbove > 0 {
+		highest := max(minPeers, p.Warning, p.Critical)
+		if highest == 0 {
+			errs = append(errs, errors.New("peer_count.recover_above requires a peer count threshold"))
+		} else if p.RecoverAbove < highest {
+			errs = append(errs, errors.Errorf("peer_count.recover_above must be at least the highest threshold %d", highest))
+		}
+	}
+	return errs
+}
+
+func (v Validators) validate() []error {
+	var errs []error
+	for _, id := range v.IDs {
+		if strings.HasPrefix(id, "0x") {
+			data, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+			if err != nil || len(data) != blsPubKeyLength {
+				errs = append(errs, errors.Errorf("invalid validator public key: %q", id))
+			}
+			continue
+		}
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			errs = append(errs, errors.Errorf("invalid validator index: %q", id))
+		}
+	}
+	if v.MaxMissedAttestations < 0 {
+		errs = append(errs, errors.New("validators.max_missed_attestations must not be negative"))
+	}
+	if v.MaxMissedProposals < 0 {
+		errs = append(errs, errors.New("validators.max_missed_proposals must not be negative"))
+	}
+	return errs
 }
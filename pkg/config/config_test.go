@@ -0,0 +1,222 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const validConfig = `
+endpoints:
+  - name: el
+    url: http://localhost:8545
+    type: execution
+    new_block_max_duration: 60s
+    poll_duration: 10s
+    slack:
+      enabled: true
+  - name: cl
+    url: http://localhost:5052
+    type: consensus
+    poll_duration: 10s
+    validators:
+      ids: ['1', '0x93247f2209abcacf57b75a51dafae777f9dd38bc7053d1af526f220a7489a6d3a2753e5f3e8b1cfe39b56f43611df74a']
+slack:
+  enabled: true
+  webhook_url: https://hooks.slack.com/services/example
+`
+
+func TestLoadConfigValid(t *testing.T) {
+	conf, err := LoadConfig([]byte(validConfig))
+	if err != nil {
+		t.Fatalf("expected valid configuration, got %v", err)
+	}
+	if len(conf.Endpoints) != 2 {
+		t.Fatalf("expected 2 endpoints, got %d", len(conf.Endpoints))
+	}
+}
+
+func TestLoadConfigReportsAllProblems(t *testing.T) {
+	data := `
+endpoints:
+  - name: el
+    url: http://localhost:8545
+    type: execution
+    new_block_max_duration: 60s
+    pagerduty:
+      enabled: true
+    opsgenie:
+      enabled: true
+      responders:
+        - type: team
+        - type: group
+          name: infra
+      priorities:
+        page: P1
+        critical: P0
+  - name: el
+    url: localhost
+    type: consensus
+    poll_duration: 10s
+    validators:
+      ids: ['abc', '0x1234']
+    webhook:
+      enabled: true
+      body: '{{ .Message '
+    email:
+      enabled: true
+      tls: ssl
+      to: ['not an address']
+      digest: -1m
+    discord:
+      enabled: true
+      webhook_url: discord.com/api/webhooks/1
+    telegram:
+      enabled: true
+      chat_id: '-100'
+    teams:
+      enabled: true
+slack:
+  enabled: true
+  channel: alerts
+opsgenie:
+  region: ap
+heartbeat:
+  url: https://hc-ping.com/example
+  max_check_age: 10s
+delivery:
+  max_attempts: -1
+  min_backoff: 10s
+  max_backoff: 1s
+`
+	_, err := LoadConfig([]byte(data))
+	if err == nil {
+		t.Fatalf("expected invalid configuration")
+	}
+
+	expected := []string{
+		`slack is enabled but neither webhook_url nor channel and token are set`,
+		`endpoint "el": poll_duration must be greater than zero`,
+		`endpoint "el": pagerduty is enabled but no routing_key is set on the endpoint or globally`,
+		`invalid opsgenie region: "ap", expected us or eu`,
+		`delivery max_attempts must not be negative`,
+		`heartbeat max_check_age 10s must be longer than the poll_duration 10s of endpoint "el"`,
+		`delivery max_backoff must not be less than min_backoff`,
+		`endpoint "el": opsgenie is enabled but no api_key is set on the endpoint or globally`,
+		`endpoint "el": opsgenie responder #1 requires id or name`,
+		`endpoint "el": invalid opsgenie responder #2 type: "group"`,
+		`endpoint "el": invalid opsgenie priorities severity: "page"`,
+		`endpoint "el": invalid opsgenie priority for critical: "P0"`,
+		`endpoint "el": duplicate endpoint name`,
+		`endpoint "el": invalid endpoint URL: localhost`,
+		`endpoint "el": invalid validator index: "abc"`,
+		`endpoint "el": invalid validator public key: "0x1234"`,
+		`endpoint "el": webhook is enabled but no url is set on the endpoint or globally`,
+		`endpoint "el": invalid webhook body template`,
+		`endpoint "el": email is enabled but no host, from or to is set on the endpoint or globally`,
+		`endpoint "el": invalid email tls: "ssl"`,
+		`endpoint "el": invalid email to address: "not an address"`,
+		`endpoint "el": email digest must not be negative`,
+		`endpoint "el": invalid discord webhook_url: discord.com/api/webhooks/1`,
+		`endpoint "el": telegram is enabled but no bot_token or chat_id is set on the endpoint or globally`,
+		`endpoint "el": teams is enabled but no webhook_url is set on the endpoint or globally`,
+	}
+	for _, msg := range expected {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("expected error to contain %q, got:\n%v", msg, err)
+		}
+	}
+}
+
+func TestEndpointValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		wantErr  string
+	}{
+		{
+			name:     "valid consensus endpoint without block monitor",
+			endpoint: Endpoint{Name: "cl", URL: "http://localhost", Type: TypeConsensus, PollDuration: 1},
+		},
+		{
+			name:     "invalid type",
+			endpoint: Endpoint{Name: "x", URL: "http://localhost", Type: "beacon", PollDuration: 1},
+			wantErr:  `invalid endpoint type: "beacon"`,
+		},
+		{
+			name:     "finality on execution endpoint",
+			endpoint: Endpoint{Name: "el", URL: "http://localhost", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, Finality: Finality{MaxStalledEpochs: 2}},
+			wantErr:  "finality is only supported on consensus endpoints",
+		},
+		{
+			name:     "finality stall below the healthy lag",
+			endpoint: Endpoint{Name: "cl", URL: "http://localhost", Type: TypeConsensus, PollDuration: 1, Finality: Finality{MaxStalledEpochs: 1}},
+			wantErr:  "finality.max_stalled_epochs must be at least 2",
+		},
+		{
+			name:     "invalid condition severity",
+			endpoint: Endpoint{Name: "el", URL: "http://localhost", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, Severity: Severities{PeerCount: "page"}},
+			wantErr:  `invalid severity for peer_count: "page"`,
+		},
+		{
+			name:     "missing jwt secret",
+			endpoint: Endpoint{Name: "el", URL: "http://localhost:8551", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, JWTSecretFile: "/nonexistent/jwt.hex"},
+			wantErr:  "failed to read jwt secret",
+		},
+		{
+			name:     "jwt secret and basic auth",
+			endpoint: Endpoint{Name: "el", URL: "http://localhost:8551", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, JWTSecretFile: "/nonexistent/jwt.hex", BasicAuth: BasicAuth{Username: "user"}},
+			wantErr:  "jwt_secret_file and basic_auth both set the Authorization header",
+		},
+		{
+			name:     "authorization header and basic auth",
+			endpoint: Endpoint{Name: "el", URL: "http://localhost", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, Headers: map[string]string{"authorization": "Bearer token"}, BasicAuth: BasicAuth{Username: "user"}},
+			wantErr:  "headers set authorization, which jwt_secret_file and basic_auth replace",
+		},
+		{
+			name:     "reference without network",
+			endpoint: Endpoint{Name: "el", URL: "http://localhost", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, Reference: true},
+			wantErr:  "network is required to compare head lag",
+		},
+		{
+			name:     "chain id on consensus endpoint",
+			endpoint: Endpoint{Name: "cl", URL: "http://localhost", Type: TypeConsensus, PollDuration: 1, ChainID: 1},
+			wantErr:  "chain_id is only supported on execution endpoints",
+		},
+		{
+			name:     "invalid genesis fork version",
+			endpoint: Endpoint{Name: "cl", URL: "http://localhost", Type: TypeConsensus, PollDuration: 1, Genesis: Genesis{ForkVersion: "0x0000"}},
+			wantErr:  `invalid genesis.fork_version: "0x0000"`,
+		},
+		{
+			name: "valid genesis",
+			endpoint: Endpoint{Name: "cl", URL: "http://localhost", Type: TypeConsensus, PollDuration: 1, Genesis: Genesis{
+				ValidatorsRoot: "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95",
+				ForkVersion:    "0x00000000",
+			}},
+		},
+		{
+			name:     "subscribe over http",
+			endpoint: Endpoint{Name: "el", URL: "http://localhost:8545", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, Subscribe: true},
+			wantErr:  "subscribe requires a ws:// or wss:// endpoint URL",
+		},
+		{
+			name:     "subscribe over websocket",
+			endpoint: Endpoint{Name: "el", URL: "ws://localhost:8546", Type: TypeExecution, PollDuration: 1, NewBlockMaxDuration: 1, Subscribe: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.endpoint.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
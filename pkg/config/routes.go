@@ -0,0 +1,282 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Receiver is a named set of alert channels that routes send alerts to. A channel is used when it is
+// configured, the enabled flag of its block is not needed.
+type Receiver struct {
+	Name      string    `yaml:"name" json:"name"`
+	Pagerduty Pagerduty `yaml:"pagerduty" json:"pagerduty"`
+	Opsgenie  Opsgenie  `yaml:"opsgenie" json:"opsgenie"`
+	Email     Email     `yaml:"email" json:"email"`
+	Slack     Slack     `yaml:"slack" json:"slack"`
+	Discord   Discord   `yaml:"discord" json:"discord"`
+	Telegram  Telegram  `yaml:"telegram" json:"telegram"`
+	Teams     Teams     `yaml:"teams" json:"teams"`
+	Webhook   Webhook   `yaml:"webhook" json:"webhook"`
+}
+
+// HasPagerduty returns true if the receiver sends alerts to PagerDuty.
+func (r Receiver) HasPagerduty() bool {
+	return r.Pagerduty.RoutingKey != ""
+}
+
+// HasOpsgenie returns true if the receiver sends alerts to Opsgenie.
+func (r Receiver) HasOpsgenie() bool {
+	return r.Opsgenie.APIKey != ""
+}
+
+// HasEmail returns true if the receiver sends alerts by email.
+func (r Receiver) HasEmail() bool {
+	return r.Email.deliverable()
+}
+
+// HasSlack returns true if the receiver sends alerts to Slack.
+func (r Receiver) HasSlack() bool {
+	return r.Slack.deliverable()
+}
+
+// HasDiscord returns true if the receiver sends alerts to Discord.
+func (r Receiver) HasDiscord() bool {
+	return r.Discord.WebhookURL != ""
+}
+
+// HasTelegram returns true if the receiver sends alerts to Telegram.
+func (r Receiver) HasTelegram() bool {
+	return r.Telegram.deliverable()
+}
+
+// HasTeams returns true if the receiver sends alerts to Microsoft Teams.
+func (r Receiver) HasTeams() bool {
+	return r.Teams.WebhookURL != ""
+}
+
+// HasWebhook returns true if the receiver sends alerts to a webhook.
+func (r Receiver) HasWebhook() bool {
+	return r.Webhook.URL != ""
+}
+
+func (r Receiver) hasChannels() bool {
+	return r.HasPagerduty() || r.HasOpsgenie() || r.HasEmail() || r.HasSlack() || r.HasDiscord() || r.HasTelegram() || r.HasTeams() || r.HasWebhook()
+}
+
+func (r Receiver) validate() []error {
+	var errs []error
+	partialSlack := !r.HasSlack() && (r.Slack.Channel != "" || r.Slack.Token != "")
+	partialEmail := !r.HasEmail() && (r.Email.Host != "" || r.Email.From != "" || len(r.Email.To) > 0)
+	partialTelegram := !r.HasTelegram() && (r.Telegram.BotToken != "" || r.Telegram.ChatID != "")
+	if partialSlack {
+		errs = append(errs, errors.New("slack requires webhook_url or channel and token"))
+	}
+	if partialEmail {
+		errs = append(errs, errors.New("email requires host, from and to"))
+	}
+	if partialTelegram {
+		errs = append(errs, errors.New("telegram requires bot_token and chat_id"))
+	}
+	if !partialSlack && !partialEmail && !partialTelegram && !r.hasChannels() {
+		errs = append(errs, errors.New("at least one alert channel is required"))
+	}
+	errs = append(errs, r.Opsgenie.validate()...)
+	errs = append(errs, r.Email.validate()...)
+	errs = append(errs, r.Discord.validate()...)
+	errs = append(errs, r.Telegram.validate()...)
+	errs = append(errs, r.Teams.validate()...)
+	errs = append(errs, r.Webhook.validate()...)
+	for _, err := range []error{
+		validateMinSeverity("pagerduty", r.Pagerduty.MinSeverity),
+		validateMinSeverity("slack", r.Slack.MinSeverity),
+	} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Route sends the alerts it matches to its receivers. Routes are evaluated in order, and evaluation
+// stops at the first matching route unless it sets continue.
+type Route struct {
+	Match     RouteMatch `yaml:"match" json:"match"`
+	Receivers []string   `yaml:"receivers" json:"receivers"`
+	Continue  bool       `yaml:"continue" json:"continue"` // Keep evaluating the following routes after this one matched
+}
+
+// EndpointMatch selects endpoints by name pattern and labels. Every set condition must match, an empty
+// match selects every endpoint.
+type EndpointMatch struct {
+	Endpoints []string          `yaml:"endpoints" json:"endpoints,omitempty"` // Endpoint name patterns, such as mainnet-*
+	Labels    map[string]string `yaml:"labels" json:"labels,omitempty"`       // Labels the endpoint must have
+}
+
+// Empty returns true if the match has no conditions.
+func (m EndpointMatch) Empty() bool {
+	return len(m.Endpoints) == 0 && len(m.Labels) == 0
+}
+
+// Matches returns true if endpoint is selected.
+func (m EndpointMatch) Matches(endpoint Endpoint) bool {
+	if len(m.Endpoints) > 0 && !slices.ContainsFunc(m.Endpoints, func(pattern string) bool {
+		matched, _ := path.Match(pattern, endpoint.Name)
+		return matched
+	}) {
+		return false
+	}
+	for key, value := range m.Labels {
+		if label, ok := endpoint.Labels[key]; !ok || label != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (m EndpointMatch) validate() []error {
+	var errs []error
+	for _, pattern := range m.Endpoints {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, errors.Errorf("invalid endpoint pattern %q", pattern))
+		}
+	}
+	return errs
+}
+
+// RouteMatch selects alerts by the endpoint and monitor raising them. Every set condition must match,
+// an empty match selects every alert.
+type RouteMatch struct {
+	EndpointMatch `yaml:",inline"`
+	Monitors      []string `yaml:"monitors" json:"monitors"`         // Monitor kinds, such as BlockNumberMonitor or PeerCountMonitor
+	MinSeverity   Severity `yaml:"min_severity" json:"min_severity"` // Alerts below this severity are not matched
+}
+
+// Matches returns true if an alert of severity raised by a monitor of kind for endpoint is selected.
+func (m RouteMatch) Matches(endpoint Endpoint, kind string, severity Severity) bool {
+	if !m.EndpointMatch.Matches(endpoint) {
+		return false
+	}
+	if len(m.Monitors) > 0 && !slices.Contains(m.Monitors, kind) {
+		return false
+	}
+	return severity.AtLeast(m.MinSeverity)
+}
+
+func (r Route) validate(receivers map[string]bool) []error {
+	var errs []error
+	if len(r.Receivers) == 0 {
+		errs = append(errs, errors.New("at least one receiver is required"))
+	}
+	for _, name := range r.Receivers {
+		if !receivers[name] {
+			errs = append(errs, errors.Errorf("unknown receiver %q", name))
+		}
+	}
+	errs = append(errs, r.Match.EndpointMatch.validate()...)
+	if err := validateMinSeverity("match", r.Match.MinSeverity); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// RouteReceivers returns the names of the receivers an alert of severity raised by a monitor of kind
+// for endpoint is routed to, in route order and without duplicates.
+func (c *Config) RouteReceivers(endpoint Endpoint, kind string, severity Severity) []string {
+	var out []string
+	for _, route := range c.Routes {
+		if !route.Match.Matches(endpoint, kind, severity) {
+			continue
+		}
+		for _, name := range route.Receivers {
+			if !slices.Contains(out, name) {
+				out = append(out, name)
+			}
+		}
+		if !route.Continue {
+			break
+		}
+	}
+	return out
+}
+
+// enabledChannels returns the names of the enabled global alert channels.
+func (c *Config) enabledChannels() []string {
+	return enabledChannels(map[string]bool{
+		"pagerduty": c.Pagerduty.Enabled,
+		"opsgenie":  c.Opsgenie.Enabled,
+		"email":     c.Email.Enabled,
+		"slack":     c.Slack.Enabled,
+		"discord":   c.Discord.Enabled,
+		"telegram":  c.Telegram.Enabled,
+		"teams":     c.Teams.Enabled,
+		"webhook":   c.Webhook.Enabled,
+	})
+}
+
+// enabledChannels returns the names of the alert channels enabled on the endpoint.
+func (e Endpoint) enabledChannels() []string {
+	return enabledChannels(map[string]bool{
+		"pagerduty": e.Pagerduty.Enabled,
+		"opsgenie":  e.Opsgenie.Enabled,
+		"email":     e.Email.Enabled,
+		"slack":     e.Slack.Enabled,
+		"discord":   e.Discord.Enabled,
+		"telegram":  e.Telegram.Enabled,
+		"teams":     e.Teams.Enabled,
+		"webhook":   e.Webhook.Enabled,
+	})
+}
+
+func enabledChannels(channels map[string]bool) []string {
+	var out []string
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		if channels[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// validateRoutes returns the problems of the receivers and routes. Routes replace the per-endpoint
+// and global alert channels, so those must not be enabled alongside them.
+func (c *Config) validateRoutes() []error {
+	var errs []error
+	receivers := map[string]bool{}
+	for i, receiver := range c.Receivers {
+		prefix := fmt.Sprintf("receiver %q", receiver.Name)
+		if receiver.Name == "" {
+			prefix = fmt.Sprintf("receiver #%d", i+1)
+			errs = append(errs, errors.Newf("%s: name is required", prefix))
+		} else if receivers[receiver.Name] {
+			errs = append(errs, errors.Newf("%s: duplicate receiver name", prefix))
+		}
+		receivers[receiver.Name] = true
+		for _, err := range receiver.validate() {
+			errs = append(errs, errors.Wrap(err, prefix))
+		}
+	}
+
+	for i, route := range c.Routes {
+		for _, err := range route.validate(receivers) {
+			errs = append(errs, errors.Wrapf(err, "route #%d", i+1))
+		}
+	}
+
+	if len(c.Routes) == 0 {
+		return errs
+	}
+	if enabled := c.enabledChannels(); len(enabled) > 0 {
+		errs = append(errs, errors.Newf("global %s channels cannot be enabled together with routes, define them as receivers", strings.Join(enabled, ", ")))
+	}
+	for _, endpoint := range c.Endpoints {
+		if enabled := endpoint.enabledChannels(); len(enabled) > 0 {
+			errs = append(errs, errors.Newf("endpoint %q: %s channels cannot be enabled together with routes, define them as receivers", endpoint.Name, strings.Join(enabled, ", ")))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const routedConfig = `
+endpoints:
+  - name: mainnet-el
+    url: http://localhost:8545
+    type: execution
+    new_block_max_duration: 60s
+    poll_duration: 10s
+    labels:
+      env: prod
+  - name: holesky-el
+    url: http://localhost:8546
+    type: execution
+    new_block_max_duration: 60s
+    poll_duration: 10s
+    labels:
+      env: test
+receivers:
+  - name: oncall
+    pagerduty:
+      routing_key: example-routing-key
+  - name: team
+    slack:
+      webhook_url: https://hooks.slack.com/services/example
+  - name: archive
+    webhook:
+      url: https://hooks.example.com/alerts
+routes:
+  - receivers: [archive]
+    continue: true
+  - match:
+      labels:
+        env: prod
+      min_severity: critical
+    receivers: [oncall, team]
+  - match:
+      endpoints: ['*-el']
+      monitors: [PeerCountMonitor]
+    receivers: [team]
+`
+
+func TestRouteReceivers(t *testing.T) {
+	conf, err := LoadConfig([]byte(routedConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mainnet, holesky := conf.Endpoints[0], conf.Endpoints[1]
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		kind     string
+		severity Severity
+		want     []string
+	}{
+		{"critical on prod", mainnet, "BlockNumberMonitor", SeverityCritical, []string{"archive", "oncall", "team"}},
+		{"error on prod", mainnet, "BlockNumberMonitor", SeverityError, []string{"archive"}},
+		{"peers on prod", mainnet, "PeerCountMonitor", SeverityWarning, []string{"archive", "team"}},
+		{"critical peers on prod stops at first match", mainnet, "PeerCountMonitor", SeverityCritical, []string{"archive", "oncall", "team"}},
+		{"critical on test", holesky, "BlockNumberMonitor", SeverityCritical, []string{"archive"}},
+		{"peers on test", holesky, "PeerCountMonitor", SeverityInfo, []string{"archive", "team"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conf.RouteReceivers(tt.endpoint, tt.kind, tt.severity); !slices.Equal(got, tt.want) {
+				t.Fatalf("unexpected receivers got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	data := routedConfig + `
+  - match:
+      endpoints: ['[']
+      min_severity: page
+    receivers: [missing]
+  - receivers: []
+slack:
+  enabled: true
+  webhook_url: https://hooks.slack.com/services/example
+`
+	data = strings.Replace(data, "  - name: archive\n    webhook:\n      url: https://hooks.example.com/alerts\n",
+		"  - name: archive\n  - name: team\n    slack:\n      channel: alerts\n", 1)
+
+	_, err := LoadConfig([]byte(data))
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		`receiver "archive": at least one alert channel is required`,
+		`receiver "team": duplicate receiver name`,
+		`receiver "team": slack requires webhook_url or channel and token`,
+		`route #4: unknown receiver "missing"`,
+		`route #4: invalid endpoint pattern "["`,
+		`route #4: invalid match min_severity: "page"`,
+		"route #5: at least one receiver is required",
+		"global slack channels cannot be enabled together with routes",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	fileRefPrefix = "file://"
+	redacted      = "[REDACTED]"
+
+	// secretPlaceholder replaces secret references when the configuration is only validated
+	secretPlaceholder = "SECRET_PLACEHOLDER"
+
+	// minSecretLength avoids redacting short values, such as a port or an index, from every log line
+	minSecretLength = 4
+)
+
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveSecrets replaces secret references in every string of the configuration: a value of the form
+// file:///path is replaced by the content of the file, and ${VAR} by the value of the environment variable.
+// Resolved values are remembered so that they can be redacted. When the configuration is only validated,
+// references are replaced by a placeholder instead.
+func (c *Config) resolveSecrets() error {
+	var errs []error
+	resolveValue(reflect.ValueOf(c).Elem(), func(value string) string {
+		if c.offline {
+			out, err := placeholdString(value)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return out
+		}
+		out, secrets, err := resolveString(value)
+		if err != nil {
+			errs = append(errs, err)
+			return value
+		}
+		for _, secret := range secrets {
+			if len(secret) >= minSecretLength {
+				c.secrets = append(c.secrets, secret)
+			}
+		}
+		return out
+	})
+
+	// Longest first, so that a secret containing another is redacted as a whole
+	sort.Slice(c.secrets, func(i, j int) bool {
+		return len(c.secrets[i]) > len(c.secrets[j])
+	})
+	return errors.Join(errs...)
+}
+
+// resolveString returns value with its references resolved, and the resolved secret values.
+func resolveString(value string) (string, []string, error) {
+	if strings.HasPrefix(value, fileRefPrefix) {
+		path := strings.TrimPrefix(value, fileRefPrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "failed to read secret file %s", path)
+		}
+		secret := strings.TrimSpace(string(data))
+		return secret, []string{secret}, nil
+	}
+
+	var (
+		secrets []string
+		errs    []error
+	)
+	out := envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRefPattern.FindStringSubmatch(ref)[1]
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			errs = append(errs, errors.Errorf("environment variable %s is not set", name))
+			return ref
+		}
+		secrets = append(secrets, secret)
+		return secret
+	})
+	return out, secrets, errors.Join(errs...)
+}
+
+// placeholdString returns value with its references replaced by the placeholder, and an error if a
+// reference is malformed.
+func placeholdString(value string) (string, error) {
+	if strings.HasPrefix(value, fileRefPrefix) {
+		if strings.TrimPrefix(value, fileRefPrefix) == "" {
+			return value, errors.New("secret file reference without a path")
+		}
+		return secretPlaceholder, nil
+	}
+	out := envRefPattern.ReplaceAllString(value, secretPlaceholder)
+	if strings.Contains(out, "${") {
+		return value, errors.Errorf("malformed environment variable reference in %q, expected ${NAME}", value)
+	}
+	return out, nil
+}
+
+// resolveValue calls resolve on every exported string reachable from v, and stores the result.
+func resolveValue(v reflect.Value, resolve func(string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(resolve(v.String()))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				resolveValue(v.Field(i), resolve)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			resolveValue(v.Index(i), resolve)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			v.SetMapIndex(key, reflect.ValueOf(resolve(v.MapIndex(key).String())).Convert(v.Type().Elem()))
+		}
+	}
+}
+
+// Redact replaces every secret resolved from a reference in s.
+func (c *Config) Redact(s string) string {
+	if c == nil {
+		return s
+	}
+	for _, secret := range c.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// redactHook redacts secrets from the message and fields of log entries.
+type redactHook struct {
+	conf *Config
+}
+
+func (h redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.conf.Redact(entry.Message)
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = h.conf.Redact(v)
+		case error:
+			entry.Data[key] = h.conf.Redact(v.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"github.com/cockroachdb/errors"
+)
+
+// Severity of an alert, matching the PagerDuty V2 event severities.
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityError    Severity = "error"
+	SeverityWarning  Severity = "warning"
+	SeverityInfo     Severity = "info"
+
+	DefaultSeverity = SeverityError
+)
+
+var severityRanks = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityError:    3,
+	SeverityCritical: 4,
+}
+
+// Valid returns true for a known severity or the empty severity, which uses the default.
+func (s Severity) Valid() bool {
+	_, ok := severityRanks[s]
+	return ok || s == ""
+}
+
+// OrDefault returns the severity, or DefaultSeverity if it is not set.
+func (s Severity) OrDefault() Severity {
+	if s == "" {
+		return DefaultSeverity
+	}
+	return s
+}
+
+// Or returns the severity, or fallback if it is not set.
+func (s Severity) Or(fallback Severity) Severity {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// AtLeast returns true if the severity is at least as severe as min. An empty min accepts every severity.
+func (s Severity) AtLeast(min Severity) bool {
+	if min == "" {
+		return true
+	}
+	return severityRanks[s.OrDefault()] >= severityRanks[min]
+}
+
+// Severities sets the severity of the alerts raised for each condition of an endpoint, unset conditions default to error
+// except chain, which defaults to critical.
+type Severities struct {
+	BlockStall Severity `yaml:"block_stall" json:"block_stall"` // No new block, or the consensus event stream is disconnected
+	PeerCount  Severity `yaml:"peer_count" json:"peer_count"`
+	SyncStatus Severity `yaml:"sync_status" json:"sync_status"`
+	HeadLag    Severity `yaml:"head_lag" json:"head_lag"`
+	Reorg      Severity `yaml:"reorg" json:"reorg"`
+	Chain      Severity `yaml:"chain" json:"chain"` // The endpoint follows a different chain than declared
+	Finality   Severity `yaml:"finality" json:"finality"`
+	Validators Severity `yaml:"validators" json:"validators"`
+}
+
+func (s Severities) validate() []error {
+	var errs []error
+	conditions := []struct {
+		name     string
+		severity Severity
+	}{
+		{"block_stall", s.BlockStall},
+		{"peer_count", s.PeerCount},
+		{"sync_status", s.SyncStatus},
+		{"head_lag", s.HeadLag},
+		{"reorg", s.Reorg},
+		{"chain", s.Chain},
+		{"finality", s.Finality},
+		{"validators", s.Validators},
+	}
+	for _, c := range conditions {
+		if !c.severity.Valid() {
+			errs = append(errs, errors.Errorf("invalid severity for %s: %q", c.name, c.severity))
+		}
+	}
+	return errs
+}
+
+func validateMinSeverity(channel string, severity Severity) error {
+	if !severity.Valid() {
+		return errors.Errorf("invalid %s min_severity: %q", channel, severity)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata" // The runtime image has no zoneinfo for recurring silence timezones
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	// recurringTimeLayout is the layout of the time of day a recurring silence starts at.
+	recurringTimeLayout = "15:04"
+
+	maxRecurringDuration = 7 * 24 * time.Hour
+)
+
+// Silence suppresses the alerts of the endpoints it matches, either between Start and End or during a
+// recurring window. Monitors keep checking and recording their state while silenced.
+type Silence struct {
+	Name      string        `yaml:"name" json:"name"`
+	Comment   string        `yaml:"comment" json:"comment,omitempty"`
+	Match     EndpointMatch `yaml:"match" json:"match"`
+	Start     time.Time     `yaml:"start" json:"start,omitzero"` // Defaults to immediately
+	End       time.Time     `yaml:"end" json:"end,omitzero"`
+	Recurring Recurring     `yaml:"recurring" json:"recurring,omitzero"`
+}
+
+// Recurring is a window repeating on the given days, such as a weekly maintenance.
+type Recurring struct {
+	Days     []string      `yaml:"days" json:"days,omitempty"` // Weekdays the window starts on, such as mon or tuesday, defaults to every day
+	At       string        `yaml:"at" json:"at"`               // Time of day the window starts at, as 15:04
+	Duration time.Duration `yaml:"duration" json:"duration"`
+	Timezone string        `yaml:"timezone" json:"timezone,omitempty"` // IANA timezone of At, defaults to UTC
+}
+
+// Enabled returns true if the silence repeats.
+func (r Recurring) Enabled() bool {
+	return r.At != "" || r.Duration > 0
+}
+
+func (r Recurring) location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(r.Timezone)
+}
+
+func (r Recurring) validate() []error {
+	var errs []error
+	if _, err := time.Parse(recurringTimeLayout, r.At); err != nil {
+		errs = append(errs, errors.Errorf("invalid recurring.at: %q, expected a time of day such as 22:00", r.At))
+	}
+	if r.Duration <= 0 || r.Duration > maxRecurringDuration {
+		errs = append(errs, errors.Errorf("recurring.duration must be greater than zero and at most %s", maxRecurringDuration))
+	}
+	for _, day := range r.Days {
+		if _, ok := parseWeekday(day); !ok {
+			errs = append(errs, errors.Errorf("invalid recurring day: %q", day))
+		}
+	}
+	if _, err := r.location(); err != nil {
+		errs = append(errs, errors.Errorf("invalid recurring.timezone: %q", r.Timezone))
+	}
+	return errs
+}
+
+// activeAt returns true if t falls in a window starting on one of the days, looking back far enough
+// for windows longer than a day that started on a previous day.
+func (r Recurring) activeAt(t time.Time) bool {
+	loc, err := r.location()
+	if err != nil {
+		return false
+	}
+	at, err := time.Parse(recurringTimeLayout, r.At)
+	if err != nil {
+		return false
+	}
+	local := t.In(loc)
+	for daysBack := 0; daysBack <= int(r.Duration/(24*time.Hour))+1; daysBack++ {
+		start := time.Date(local.Year(), local.Month(), local.Day()-daysBack, at.Hour(), at.Minute(), 0, 0, loc)
+		if !r.onDay(start.Weekday()) {
+			continue
+		}
+		if !t.Before(start) && t.Before(start.Add(r.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Recurring) onDay(weekday time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.Days, func(day string) bool {
+		d, ok := parseWeekday(day)
+		return ok && d == weekday
+	})
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(day)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if day == name || day == name[:3] {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// ActiveAt returns true if the silence suppresses alerts at t.
+func (s Silence) ActiveAt(t time.Time) bool {
+	if s.Recurring.Enabled() {
+		return s.Recurring.activeAt(t)
+	}
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// Expired returns true if a one-off silence has ended by t. Recurring silences never expire.
+func (s Silence) Expired(t time.Time) bool {
+	return !s.Recurring.Enabled() && !t.Before(s.End)
+}
+
+// Validate returns all problems of the silence.
+func (s Silence) Validate() error {
+	return errors.Join(s.validate()...)
+}
+
+func (s Silence) validate() []error {
+	var errs []error
+	if s.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if s.Match.Empty() {
+		errs = append(errs, errors.New("match requires endpoints or labels, use endpoints: [\"*\"] to silence every endpoint"))
+	}
+	errs = append(errs, s.Match.validate()...)
+	if s.Recurring.Enabled() {
+		if !s.Start.IsZero() || !s.End.IsZero() {
+			errs = append(errs, errors.New("start and end cannot be set on a recurring silence"))
+		}
+		errs = append(errs, s.Recurring.validate()...)
+	} else if s.End.IsZero() {
+		errs = append(errs, errors.New("end or recurring is required"))
+	} else if !s.End.After(s.Start) {
+		errs = append(errs, errors.New("end must be after start"))
+	}
+	return errs
+}
+
+// validateSilences returns the problems of the configured silences.
+func (c *Config) validateSilences() []error {
+	var errs []error
+	names := map[string]bool{}
+	for i, silence := range c.Silences {
+		prefix := fmt.Sprintf("silence %q", silence.Name)
+		if silence.Name == "" {
+			prefix = fmt.Sprintf("silence #%d", i+1)
+		} else if names[silence.Name] {
+			errs = append(errs, errors.Newf("%s: duplicate silence name", prefix))
+		}
+		names[silence.Name] = true
+		for _, err := range silence.validate() {
+			errs = append(errs, errors.Wrap(err, prefix))
+		}
+	}
+	return errs
+}
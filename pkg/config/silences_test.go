@@ -0,0 +1,133 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const silencedConfig = `
+endpoints:
+  - name: mainnet-el
+    url: http://localhost:8545
+    type: execution
+    new_block_max_duration: 60s
+    poll_duration: 10s
+    labels:
+      env: prod
+silences:
+  - name: upgrade
+    comment: client upgrade
+    match:
+      endpoints: [mainnet-*]
+    start: 2026-03-01T10:00:00Z
+    end: 2026-03-01T12:00:00Z
+  - name: weekly-maintenance
+    match:
+      labels:
+        env: prod
+    recurring:
+      days: [sat, Sunday]
+      at: "23:00"
+      duration: 2h
+      timezone: Europe/Berlin
+`
+
+func TestSilenceActiveAt(t *testing.T) {
+	conf, err := LoadConfig([]byte(silencedConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	upgrade, weekly := conf.Silences[0], conf.Silences[1]
+
+	tests := []struct {
+		name    string
+		silence Silence
+		at      string
+		want    bool
+	}{
+		{"before start", upgrade, "2026-03-01T09:59:59Z", false},
+		{"at start", upgrade, "2026-03-01T10:00:00Z", true},
+		{"at end", upgrade, "2026-03-01T12:00:00Z", false},
+		// 2026-03-07 is a Saturday, Berlin is UTC+1 in March
+		{"recurring start", weekly, "2026-03-07T22:00:00Z", true},
+		{"recurring past midnight", weekly, "2026-03-07T23:59:00Z", true},
+		{"recurring end", weekly, "2026-03-08T00:00:00Z", false},
+		{"recurring other day", weekly, "2026-03-05T22:30:00Z", false},
+		{"recurring sunday", weekly, "2026-03-08T22:30:00Z", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.silence.ActiveAt(at); got != tt.want {
+				t.Fatalf("unexpected active got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurringSilenceSpanningDays(t *testing.T) {
+	silence := Silence{
+		Name:      "long",
+		Match:     EndpointMatch{Endpoints: []string{"*"}},
+		Recurring: Recurring{Days: []string{"fri"}, At: "18:00", Duration: 64 * time.Hour},
+	}
+	// 2026-03-06 is a Friday, the window lasts until Monday 10:00
+	for at, want := range map[string]bool{
+		"2026-03-06T17:59:00Z": false,
+		"2026-03-08T12:00:00Z": true,
+		"2026-03-09T09:59:00Z": true,
+		"2026-03-09T10:00:00Z": false,
+	} {
+		ts, _ := time.Parse(time.RFC3339, at)
+		if got := silence.ActiveAt(ts); got != want {
+			t.Errorf("unexpected active at %s got %v want %v", at, got, want)
+		}
+	}
+}
+
+func TestValidateSilences(t *testing.T) {
+	data := silencedConfig + `
+  - name: upgrade
+    match:
+      endpoints: ['[']
+    end: 2026-03-01T12:00:00Z
+  - name: everything
+    start: 2026-03-01T12:00:00Z
+    end: 2026-03-01T10:00:00Z
+  - name: mixed
+    match:
+      endpoints: ['*']
+    end: 2026-03-01T12:00:00Z
+    recurring:
+      days: [someday]
+      at: "25:00"
+      timezone: Mars/Olympus
+  - match:
+      endpoints: ['*']
+`
+	_, err := LoadConfig([]byte(data))
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		`silence "upgrade": duplicate silence name`,
+		`silence "upgrade": invalid endpoint pattern "["`,
+		`silence "everything": match requires endpoints or labels`,
+		`silence "everything": end must be after start`,
+		`silence "mixed": start and end cannot be set on a recurring silence`,
+		`silence "mixed": invalid recurring.at: "25:00"`,
+		`silence "mixed": recurring.duration must be greater than zero`,
+		`silence "mixed": invalid recurring day: "someday"`,
+		`silence "mixed": invalid recurring.timezone: "Mars/Olympus"`,
+		"silence #6: name is required",
+		"silence #6: end or recurring is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package heartbeat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+// Heartbeat requests the heartbeat URL on an interval while every registered monitor is making progress, so
+// that an external watchdog notices when the process is down or one of its monitors is stuck.
+type Heartbeat struct {
+	settings config.Heartbeat
+	registry *monitor.Registry
+	client   *http.Client
+	log      logrus.Ext1FieldLogger
+	now      func() time.Time
+}
+
+func New(conf *config.Config, registry *monitor.Registry) *Heartbeat {
+	return &Heartbeat{
+		settings: conf.Heartbeat.OrDefault(),
+		registry: registry,
+		client:   &http.Client{Timeout: conf.RPCTimeout},
+		log:      conf.Log.WithField("name", "heartbeat"),
+		now:      time.Now,
+	}
+}
+
+// Run sends a heartbeat every interval until ctx is done.
+func (h *Heartbeat) Run(ctx context.Context) {
+	h.log.WithField("interval", h.settings.Interval).Info("heartbeat started")
+	ticker := time.NewTicker(h.settings.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.beat(ctx)
+		case <-ctx.Done():
+			h.log.Info("heartbeat stopped")
+			return
+		}
+	}
+}
+
+// beat requests the heartbeat URL, unless no monitor is running or one of them is stuck.
+func (h *Heartbeat) beat(ctx context.Context) {
+	statuses := h.registry.Statuses()
+	if len(statuses) == 0 {
+		metrics.Heartbeats.WithLabelValues("skipped").Inc()
+		h.log.Error("no monitors running, skipping heartbeat")
+		return
+	}
+	if stuck := h.stuck(statuses); len(stuck) > 0 {
+		metrics.Heartbeats.WithLabelValues("skipped").Inc()
+		h.log.WithFields(logrus.Fields{
+			"monitors":      stuck,
+			"max_check_age": h.settings.MaxCheckAge,
+		}).Error("monitors are not making progress, skipping heartbeat")
+		return
+	}
+	if err := h.ping(ctx); err != nil {
+		metrics.Heartbeats.WithLabelValues("failed").Inc()
+		h.log.WithError(err).Warn("failed to send heartbeat")
+		return
+	}
+	metrics.Heartbeats.WithLabelValues("sent").Inc()
+	h.log.Debug("heartbeat sent")
+}
+
+// stuck returns the names of the monitors that have not completed a check within max_check_age, since
+// their last check or since they started if they have not completed any.
+func (h *Heartbeat) stuck(statuses []monitor.Status) []string {
+	now := h.now()
+	var out []string
+	for _, status := range statuses {
+		last := status.LastCheck
+		if last.IsZero() {
+			last = status.Started
+		}
+		if now.Sub(last) > h.settings.MaxCheckAge {
+			out = append(out, status.Name)
+		}
+	}
+	return out
+}
+
+func (h *Heartbeat) ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.settings.URL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create heartbeat request")
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		// The URL of the watchdog usually embeds its token, so it is left out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return errors.Wrap(err, "failed to send heartbeat request")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("heartbeat returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package heartbeat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+type fakeMonitor struct {
+	name    string
+	tracker *monitor.Tracker
+}
+
+func newFakeMonitor(name string) *fakeMonitor {
+	return &fakeMonitor{name: name, tracker: monitor.NewTracker(&config.Config{}, name, "ep")}
+}
+
+func (f *fakeMonitor) Run(ctx context.Context) {}
+func (f *fakeMonitor) Name() string            { return f.name }
+func (f *fakeMonitor) Status() monitor.Status  { return f.tracker.Status() }
+
+func newTestHeartbeat(t *testing.T, registry *monitor.Registry, status int) (*Heartbeat, *atomic.Int32) {
+	t.Helper()
+	pings := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	conf := &config.Config{
+		Log:        logrus.New(),
+		RPCTimeout: time.Second,
+		Heartbeat:  config.Heartbeat{URL: srv.URL + "/ping/secret-uuid", MaxCheckAge: time.Minute},
+	}
+	return New(conf, registry), pings
+}
+
+func TestHeartbeat_PingsWhileMonitorsProgress(t *testing.T) {
+	registry := monitor.NewRegistry()
+	mon := newFakeMonitor("execution::BlockNumberMonitor::el")
+	mon.tracker.Record(nil, nil)
+	registry.Register(mon)
+	h, pings := newTestHeartbeat(t, registry, http.StatusOK)
+
+	h.beat(t.Context())
+	if pings.Load() != 1 {
+		t.Fatalf("expected a heartbeat, got %d", pings.Load())
+	}
+
+	h.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	h.beat(t.Context())
+	if pings.Load() != 1 {
+		t.Fatalf("expected no heartbeat while the monitor is stuck, got %d", pings.Load())
+	}
+}
+
+func TestHeartbeat_Stuck(t *testing.T) {
+	h, _ := newTestHeartbeat(t, monitor.NewRegistry(), http.StatusOK)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	stuck := h.stuck([]monitor.Status{
+		{Name: "checked", Started: now.Add(-time.Hour), LastCheck: now.Add(-time.Second)},
+		{Name: "starting", Started: now.Add(-time.Second)},
+		{Name: "never-checked", Started: now.Add(-2 * time.Minute)},
+		{Name: "stalled", Started: now.Add(-time.Hour), LastCheck: now.Add(-2 * time.Minute)},
+	})
+	if strings.Join(stuck, ",") != "never-checked,stalled" {
+		t.Fatalf("unexpected stuck monitors: %v", stuck)
+	}
+}
+
+func TestHeartbeat_SkippedWithoutMonitors(t *testing.T) {
+	h, pings := newTestHeartbeat(t, monitor.NewRegistry(), http.StatusOK)
+	h.beat(t.Context())
+	if pings.Load() != 0 {
+		t.Fatalf("expected no heartbeat without monitors, got %d", pings.Load())
+	}
+}
+
+func TestHeartbeat_PingErrorHidesURL(t *testing.T) {
+	registry := monitor.NewRegistry()
+	registry.Register(newFakeMonitor("execution::BlockNumberMonitor::el"))
+	h, _ := newTestHeartbeat(t, registry, http.StatusServiceUnavailable)
+
+	if err := h.ping(t.Context()); err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Fatalf("expected a status error, got %v", err)
+	}
+	h.settings.URL = "http://127.0.0.1:0/ping/secret-uuid"
+	if err := h.ping(t.Context()); err == nil || strings.Contains(err.Error(), "secret-uuid") {
+		t.Fatalf("expected an error without the URL, got %v", err)
+	}
+}
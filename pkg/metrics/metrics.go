@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "eth_monitor"
+
+var (
+	BlockNumber = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "block_number",
+		Help:      "Last block number observed on an execution endpoint.",
+	}, []string{"endpoint"})
+
+	LastNewBlockAge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_new_block_age_seconds",
+		Help:      "Seconds since the endpoint last produced a new block.",
+	}, []string{"endpoint", "type"})
+
+	PeerCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "peer_count",
+		Help:      "Last peer count observed on an endpoint.",
+	}, []string{"endpoint", "type"})
+
+	Slot = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "slot",
+		Help:      "Last slot observed on a consensus endpoint.",
+	}, []string{"endpoint"})
+
+	FinalizedEpoch = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "finalized_epoch",
+		Help:      "Finalized checkpoint epoch observed on a consensus endpoint.",
+	}, []string{"endpoint"})
+
+	JustifiedEpoch = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "justified_epoch",
+		Help:      "Current justified checkpoint epoch observed on a consensus endpoint.",
+	}, []string{"endpoint"})
+
+	ValidatorBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "validator_balance_gwei",
+		Help:      "Balance of a monitored validator.",
+	}, []string{"endpoint", "validator"})
+
+	ValidatorMissedAttestations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validator_missed_attestations_total",
+		Help:      "Number of epochs in which a monitored validator was not seen attesting.",
+	}, []string{"endpoint"})
+
+	ValidatorMissedProposals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validator_missed_proposals_total",
+		Help:      "Number of block proposals missed by monitored validators.",
+	}, []string{"endpoint"})
+
+	SyncCurrentBlock = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sync_current_block",
+		Help:      "Current block reported by eth_syncing, zero when the node is not syncing.",
+	}, []string{"endpoint"})
+
+	SyncHighestBlock = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sync_highest_block",
+		Help:      "Highest block reported by eth_syncing, zero when the node is not syncing.",
+	}, []string{"endpoint"})
+
+	SyncDistance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sync_distance_blocks",
+		Help:      "Number of blocks a syncing node is behind the highest block it knows of.",
+	}, []string{"endpoint"})
+
+	HeadLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "head_lag",
+		Help:      "Blocks or slots an endpoint is behind the best reference head of its network.",
+	}, []string{"endpoint", "type"})
+
+	Reorgs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reorgs_total",
+		Help:      "Number of chain reorganizations observed on an endpoint.",
+	}, []string{"endpoint", "type"})
+
+	ReorgDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reorg_depth",
+		Help:      "Depth in blocks or slots of the last chain reorganization observed on an endpoint.",
+	}, []string{"endpoint", "type"})
+
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of RPC calls made to monitored endpoints.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint", "method"})
+
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_errors_total",
+		Help:      "Number of failed RPC calls made to monitored endpoints.",
+	}, []string{"endpoint", "method"})
+
+	AlertsRaised = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_total",
+		Help:      "Number of alert notifications delivered, by channel and status.",
+	}, []string{"channel", "status"})
+
+	AlertsSilenced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_silenced_total",
+		Help:      "Number of alert notifications suppressed by a silence, by channel.",
+	}, []string{"channel"})
+
+	AlertErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alert_errors_total",
+		Help:      "Number of alert notifications that failed to be delivered, by channel.",
+	}, []string{"channel"})
+
+	AlertRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alert_retries_total",
+		Help:      "Number of retried alert deliveries, by channel.",
+	}, []string{"channel"})
+
+	AlertsDeadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_dead_lettered_total",
+		Help:      "Number of alert notifications given up on and written to the dead-letter log, by channel.",
+	}, []string{"channel"})
+
+	AlertQueueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "alert_queue_length",
+		Help:      "Number of alert notifications waiting for delivery, by channel.",
+	}, []string{"channel"})
+
+	Heartbeats = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "heartbeats_total",
+		Help:      "Number of heartbeats, by result: sent, failed, or skipped as a monitor is not making progress.",
+	}, []string{"result"})
+)
+
+// endpointMetrics are the metrics labelled by endpoint.
+var endpointMetrics = []interface {
+	DeletePartialMatch(labels prometheus.Labels) int
+}{
+	BlockNumber, LastNewBlockAge, PeerCount, Slot, FinalizedEpoch, JustifiedEpoch,
+	ValidatorBalance, ValidatorMissedAttestations, ValidatorMissedProposals,
+	SyncCurrentBlock, SyncHighestBlock, SyncDistance, HeadLag, Reorgs, ReorgDepth,
+	RPCDuration, RPCErrors,
+}
+
+// DeleteEndpoint deletes the series of an endpoint removed from the configuration, so that its last values are not
+// exported for as long as the process runs.
+func DeleteEndpoint(endpoint string) {
+	for _, metric := range endpointMetrics {
+		metric.DeletePartialMatch(prometheus.Labels{"endpoint": endpoint})
+	}
+}
+
+// ObserveRPC records the latency of an RPC call started at start, and counts it as failed if err is not nil.
+func ObserveRPC(endpoint string, method string, start time.Time, err error) {
+	RPCDuration.WithLabelValues(endpoint, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(endpoint, method).Inc()
+	}
+}
+
+// Handler returns the HTTP handler serving all registered metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -2,97 +2,238 @@ package consensus
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
-	"github.com/attestantio/go-eth2-client/api"
 	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/cockroachdb/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/backoff"
 	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
 	"github.com/numbergroup/eth-monitor/pkg/monitor"
 )
 
-type BlockMonitorETH2RPC interface {
-	Events(context.Context, *api.EventsOpts) error
-}
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// errStreamReconnecting is returned by checkBlocks while the events stream is disconnected within its grace
+// period, when whether blocks are produced is unknown, so that the alert is neither triggered nor resolved.
+var errStreamReconnecting = errors.New("block event stream disconnected, reconnecting within the grace period")
 
 type blockMonitor struct {
-	conf             *config.Config
-	client           BlockMonitorETH2RPC
-	endpoint         config.Endpoint
-	alertChannels    []alert.Alert
-	lastSlot         phase0.Slot
-	lastNewBlockTime time.Time
-	log              logrus.Ext1FieldLogger
+	conf     *config.Config
+	client   EventSubscriber
+	endpoint config.Endpoint
+	alerts   *alert.Lifecycle
+	log      logrus.Ext1FieldLogger
+	tracker  *monitor.Tracker
+
+	mu                sync.Mutex
+	lastSlot          phase0.Slot
+	lastNewBlockTime  time.Time
+	connected         bool
+	disconnectedSince time.Time
+	streamErr         error
 }
 
-func NewBlockMonitor(conf *config.Config, client BlockMonitorETH2RPC, endpoint config.Endpoint, alertChannels []alert.Alert) monitor.Monitor {
+func NewBlockMonitor(conf *config.Config, client EventSubscriber, endpoint config.Endpoint, alertChannels []alert.Alert) monitor.Monitor {
 	out := &blockMonitor{
-		conf:          conf,
-		client:        client,
-		endpoint:      endpoint,
-		alertChannels: alertChannels,
+		conf:     conf,
+		client:   client,
+		endpoint: endpoint,
 	}
 
 	out.log = conf.Log.WithFields(logrus.Fields{
 		"name":     out.Name(),
 		"endpoint": endpoint.Name,
 	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
 
 	return out
 }
 
-func (bm blockMonitor) Name() string {
+func (bm *blockMonitor) Name() string {
 	return "consensus::BlockMonitor::" + bm.endpoint.Name
 }
 
 func (bm *blockMonitor) blockEventListen(ctx context.Context) error {
-	return bm.client.Events(ctx, &api.EventsOpts{
-		Topics: []string{"block"},
-		BlockHandler: func(ctx context.Context, block *apiv1.BlockEvent) {
-			bm.log.WithField("block", block).Debug("New block received")
-
-			if block.Slot > bm.lastSlot {
-				bm.lastSlot = block.Slot
-			}
+	return bm.client.Subscribe(ctx, []string{"block"}, bm.onConnect, func(event Event) {
+		block := &apiv1.BlockEvent{}
+		if err := json.Unmarshal(event.Data, block); err != nil {
+			bm.log.WithError(err).WithField("topic", event.Topic).Warn("failed to decode block event")
+			return
+		}
+		bm.log.WithField("block", block).Debug("New block received")
 
-			bm.lastNewBlockTime = time.Now()
+		bm.mu.Lock()
+		defer bm.mu.Unlock()
+		if block.Slot > bm.lastSlot {
+			bm.lastSlot = block.Slot
+			metrics.Slot.WithLabelValues(bm.endpoint.Name).Set(float64(block.Slot))
+		}
 
-		},
+		bm.lastNewBlockTime = time.Now()
 	})
 }
 
+func (bm *blockMonitor) onConnect() {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.connected = true
+	bm.streamErr = nil
+	// Blocks could not be observed while disconnected, so only count the time since reconnecting
+	if !bm.disconnectedSince.IsZero() && bm.lastNewBlockTime.Before(bm.disconnectedSince) {
+		bm.lastNewBlockTime = time.Now()
+	}
+	bm.log.Info("connected to events stream")
+}
+
+func (bm *blockMonitor) onDisconnect(err error) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	if bm.connected || bm.disconnectedSince.IsZero() {
+		bm.disconnectedSince = time.Now()
+	}
+	bm.connected = false
+	bm.streamErr = err
+}
+
+// listen keeps the events stream subscribed, reconnecting with exponential backoff until ctx is cancelled.
+func (bm *blockMonitor) listen(ctx context.Context) {
+	retry := backoff.New(minReconnectDelay, maxReconnectDelay)
+	for {
+		err := bm.blockEventListen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		bm.mu.Lock()
+		wasConnected := bm.connected
+		bm.mu.Unlock()
+		if wasConnected {
+			retry.Reset()
+		}
+		bm.onDisconnect(err)
+
+		delay := retry.Next()
+		bm.log.WithError(err).WithFields(logrus.Fields{
+			"attempt": retry.Attempt(),
+			"delay":   delay,
+		}).Warn("block event stream disconnected, reconnecting")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// gracePeriod is how long the events stream may be disconnected before alerting.
+func (bm *blockMonitor) gracePeriod() time.Duration {
+	if bm.endpoint.StreamGracePeriod > 0 {
+		return bm.endpoint.StreamGracePeriod
+	}
+	return bm.endpoint.NewBlockMaxDuration
+}
+
+// checkBlocks returns an error describing why the endpoint is unhealthy, distinguishing a
+// disconnected events stream from a connected stream that delivers no blocks. It returns
+// errStreamReconnecting while the stream is disconnected within the grace period.
+func (bm *blockMonitor) checkBlocks() error {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if !bm.connected && !bm.disconnectedSince.IsZero() {
+		downFor := time.Since(bm.disconnectedSince)
+		if downFor > bm.gracePeriod() {
+			return fmt.Errorf("block event stream disconnected for %d seconds, expected to reconnect within %d seconds: %v",
+				int64(downFor.Seconds()), int64(bm.gracePeriod().Seconds()), bm.streamErr)
+		}
+		// Still within the grace period, missing blocks are explained by the disconnect
+		return errStreamReconnecting
+	}
+
+	if elapsed := time.Since(bm.lastNewBlockTime); elapsed > bm.endpoint.NewBlockMaxDuration {
+		return fmt.Errorf("no new block for %d seconds, expected less than %d seconds", int64(elapsed.Seconds()), int64(bm.endpoint.NewBlockMaxDuration.Seconds()))
+	}
+	return nil
+}
+
+func (bm *blockMonitor) Status() monitor.Status {
+	return bm.tracker.Status()
+}
+
+// check checks the blocks received, and triggers or resolves the alert. Neither is done while the events stream
+// is reconnecting within its grace period, as whether the endpoint is healthy is not known then.
+func (bm *blockMonitor) check(ctx context.Context) {
+	bm.mu.Lock()
+	lastSlot, lastNewBlockTime, connected := bm.lastSlot, bm.lastNewBlockTime, bm.connected
+	bm.mu.Unlock()
+
+	metrics.LastNewBlockAge.WithLabelValues(bm.endpoint.Name, config.TypeConsensus).Set(time.Since(lastNewBlockTime).Seconds())
+	err := bm.checkBlocks()
+	reconnecting := errors.Is(err, errStreamReconnecting)
+	if reconnecting {
+		err = nil
+	}
+	bm.tracker.Record(map[string]any{
+		"slot":             uint64(lastSlot),
+		"last_new_block":   lastNewBlockTime,
+		"stream_connected": connected,
+	}, err)
+	if reconnecting {
+		bm.log.Debug("block event stream reconnecting, keeping the alert as it is")
+		return
+	}
+	if err != nil {
+		bm.log.WithError(err).Error("health check failed, raising alert")
+		alertErr := bm.alerts.Trigger(ctx, alert.Message{
+			Message:  err.Error(),
+			Severity: bm.endpoint.Severity.BlockStall.OrDefault(),
+		})
+		if alertErr != nil {
+			bm.log.WithError(alertErr).Error("failed to raise alert")
+		}
+		return
+	}
+	bm.log.WithFields(logrus.Fields{
+		"slot": lastSlot}).Info("Endpoint is healthy")
+	if alertErr := bm.alerts.Resolve(ctx); alertErr != nil {
+		bm.log.WithError(alertErr).Error("failed to resolve alert")
+	}
+}
+
 func (bm *blockMonitor) Run(ctx context.Context) {
+	bm.mu.Lock()
+	bm.lastNewBlockTime = time.Now()
+	bm.mu.Unlock()
 
+	// The event stream stops with ctx, Run returns once it has
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	wg.Add(1)
 	go func() {
-		if err := bm.blockEventListen(ctx); err != nil {
-			bm.log.WithError(err).Panic("block event listener exited with error")
-			// TODO: we might want to either alert here, or reconnect
-		}
+		defer wg.Done()
+		bm.listen(ctx)
 	}()
-	bm.lastNewBlockTime = time.Now()
+
 	for {
 		select {
 		case <-ctx.Done():
 			bm.log.Info("monitoring stopped")
 			return
 		default:
-			if time.Since(bm.lastNewBlockTime) > bm.endpoint.NewBlockMaxDuration {
-				alertErr := alert.RaiseAll(ctx, bm.log, bm.alertChannels, alert.Message{
-					Message:  fmt.Sprintf("no new block for %d seconds, expected less than %d seconds", int64(time.Since(bm.lastNewBlockTime).Seconds()), int64(bm.endpoint.NewBlockMaxDuration.Seconds())),
-					Severity: alert.Error,
-					Name:     bm.endpoint.Name,
-				})
-				if alertErr != nil {
-					bm.log.WithError(alertErr).Error("failed to raise alert")
-				}
-			} else {
-				bm.log.WithFields(logrus.Fields{
-					"slot": bm.lastSlot}).Info("Endpoint is healthy")
-			}
+			bm.check(ctx)
 
 			select {
 			case <-time.After(bm.endpoint.PollDuration):
@@ -0,0 +1,135 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// fakeSubscriber delivers the configured events, then returns err.
+type fakeSubscriber struct {
+	events []Event
+	err    error
+}
+
+func (f *fakeSubscriber) Subscribe(ctx context.Context, topics []string, onConnect func(), handler func(Event)) error {
+	onConnect()
+	for _, e := range f.events {
+		handler(e)
+	}
+	return f.err
+}
+
+func newTestBlockMonitor(t *testing.T, sub EventSubscriber, ep config.Endpoint) *blockMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	bm, ok := NewBlockMonitor(conf, sub, ep, nil).(*blockMonitor)
+	if !ok {
+		t.Fatal("unexpected monitor type")
+	}
+	return bm
+}
+
+func TestBlockMonitor_RecordsSlots(t *testing.T) {
+	sub := &fakeSubscriber{events: []Event{
+		{Topic: "block", Data: []byte(`{"slot":"5","block":"0x0000000000000000000000000000000000000000000000000000000000000001","execution_optimistic":false}`)},
+	}}
+	bm := newTestBlockMonitor(t, sub, config.Endpoint{NewBlockMaxDuration: time.Minute})
+
+	_ = bm.blockEventListen(t.Context())
+	if bm.lastSlot != 5 {
+		t.Fatalf("expected lastSlot=5, got %d", bm.lastSlot)
+	}
+	if err := bm.checkBlocks(); err != nil {
+		t.Fatalf("expected healthy, got %v", err)
+	}
+}
+
+func TestBlockMonitor_NoBlocks(t *testing.T) {
+	bm := newTestBlockMonitor(t, &fakeSubscriber{}, config.Endpoint{NewBlockMaxDuration: time.Second})
+	bm.connected = true
+	bm.lastNewBlockTime = time.Now().Add(-time.Minute)
+
+	err := bm.checkBlocks()
+	if err == nil || !strings.Contains(err.Error(), "no new block") {
+		t.Fatalf("expected no new block error, got %v", err)
+	}
+}
+
+func TestBlockMonitor_DisconnectedWithinGrace_Unknown(t *testing.T) {
+	bm := newTestBlockMonitor(t, &fakeSubscriber{}, config.Endpoint{NewBlockMaxDuration: time.Second, StreamGracePeriod: time.Minute})
+	bm.lastNewBlockTime = time.Now().Add(-time.Minute)
+	bm.onDisconnect(errors.New("boom"))
+
+	if err := bm.checkBlocks(); !errors.Is(err, errStreamReconnecting) {
+		t.Fatalf("expected the stream to be reported as reconnecting within the grace period, got %v", err)
+	}
+}
+
+// countingChannel counts the triggered and resolved messages raised on it.
+type countingChannel struct {
+	triggered int
+	resolved  int
+}
+
+func (c *countingChannel) Raise(ctx context.Context, msg alert.Message) error {
+	if msg.Resolved() {
+		c.resolved++
+	} else {
+		c.triggered++
+	}
+	return nil
+}
+
+func (c *countingChannel) Name() string                { return "counting" }
+func (c *countingChannel) MinSeverity() alert.Severity { return "" }
+
+func TestBlockMonitor_DisconnectWithinGraceKeepsAlert(t *testing.T) {
+	ch := &countingChannel{}
+	conf := &config.Config{Log: logrus.New()}
+	bm, ok := NewBlockMonitor(conf, &fakeSubscriber{}, config.Endpoint{Name: "cl", NewBlockMaxDuration: time.Second, StreamGracePeriod: time.Minute}, []alert.Alert{ch}).(*blockMonitor)
+	if !ok {
+		t.Fatal("unexpected monitor type")
+	}
+	bm.connected = true
+	bm.lastNewBlockTime = time.Now().Add(-time.Minute)
+	bm.check(t.Context())
+	if ch.triggered != 1 {
+		t.Fatalf("expected the stalled blocks to trigger an alert, got %d triggers", ch.triggered)
+	}
+
+	bm.onDisconnect(errors.New("boom"))
+	bm.check(t.Context())
+	if ch.resolved != 0 || !bm.alerts.Active() {
+		t.Fatal("expected the alert to stay open while the stream reconnects")
+	}
+}
+
+func TestBlockMonitor_DisconnectedPastGrace_Error(t *testing.T) {
+	bm := newTestBlockMonitor(t, &fakeSubscriber{}, config.Endpoint{NewBlockMaxDuration: time.Second, StreamGracePeriod: time.Second})
+	bm.onDisconnect(errors.New("boom"))
+	bm.disconnectedSince = time.Now().Add(-time.Minute)
+
+	err := bm.checkBlocks()
+	if err == nil || !strings.Contains(err.Error(), "stream disconnected") {
+		t.Fatalf("expected stream disconnected error, got %v", err)
+	}
+}
+
+func TestBlockMonitor_ReconnectResetsBlockTimer(t *testing.T) {
+	bm := newTestBlockMonitor(t, &fakeSubscriber{}, config.Endpoint{NewBlockMaxDuration: time.Second})
+	bm.lastNewBlockTime = time.Now().Add(-time.Hour)
+	bm.onDisconnect(errors.New("boom"))
+	bm.onConnect()
+
+	if err := bm.checkBlocks(); err != nil {
+		t.Fatalf("expected block timer to restart on reconnect, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package consensus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/auth"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const maxEventSize = 1024 * 1024
+
+// Event is a single server-sent event received from the beacon node events stream.
+type Event struct {
+	Topic string
+	Data  []byte
+}
+
+// EventSubscriber subscribes to the beacon node events stream. Subscribe blocks
+// until the stream ends, calling onConnect once the stream is established and
+// handler for each event. It returns nil only if ctx is cancelled.
+type EventSubscriber interface {
+	Subscribe(ctx context.Context, topics []string, onConnect func(), handler func(Event)) error
+}
+
+type eventStream struct {
+	endpoint config.Endpoint
+	client   *http.Client
+}
+
+// NewEventStream returns an EventSubscriber reading /eth/v1/events from the endpoint.
+// Unlike the go-eth2-client events subscription, it surfaces disconnects to the caller
+// so that reconnects can be handled and alerted on.
+func NewEventStream(endpoint config.Endpoint) EventSubscriber {
+	return &eventStream{
+		endpoint: endpoint,
+		// No client timeout, the stream is long lived and bound by the context instead
+		client: auth.NewClient(endpoint, 0),
+	}
+}
+
+func (es *eventStream) Subscribe(ctx context.Context, topics []string, onConnect func(), handler func(Event)) error {
+	reqURL, err := url.JoinPath(es.endpoint.URL, "/eth/v1/events")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create events URL for endpoint %s", es.endpoint.Name)
+	}
+	query := url.Values{"topics": topics}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create events request for endpoint %s", es.endpoint.Name)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := es.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to connect to events stream for endpoint %s", es.endpoint.Name)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d from events stream for endpoint %s", resp.StatusCode, es.endpoint.Name)
+	}
+	onConnect()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var (
+		topic string
+		data  bytes.Buffer
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line dispatches the event accumulated so far
+			if data.Len() > 0 {
+				handler(Event{Topic: topic, Data: bytes.Clone(data.Bytes())})
+			}
+			topic = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Comment, used by some servers as a keep-alive
+		case strings.HasPrefix(line, "event:"):
+			topic = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "events stream for endpoint %s failed", es.endpoint.Name)
+	}
+	return errors.Errorf("events stream for endpoint %s closed by server", es.endpoint.Name)
+}
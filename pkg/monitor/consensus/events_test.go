@@ -0,0 +1,79 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func TestEventStream_ParsesEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/eth/v1/events" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query()["topics"]; len(got) != 1 || got[0] != "block" {
+			t.Errorf("unexpected topics %v", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: block\ndata: {\"slot\":\"10\"}\n\n")
+		fmt.Fprint(w, "event: block\ndata: {\"slot\":\"11\"}\n\n")
+	}))
+	defer srv.Close()
+
+	es := NewEventStream(config.Endpoint{Name: "test", URL: srv.URL})
+
+	connected := false
+	var events []Event
+	err := es.Subscribe(t.Context(), []string{"block"}, func() { connected = true }, func(e Event) {
+		events = append(events, e)
+	})
+	if err == nil || !strings.Contains(err.Error(), "closed by server") {
+		t.Fatalf("expected closed by server error, got %v", err)
+	}
+	if !connected {
+		t.Fatal("expected onConnect to be called")
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Topic != "block" || string(events[0].Data) != `{"slot":"10"}` {
+		t.Fatalf("unexpected first event: %+v", events[0])
+	}
+}
+
+func TestEventStream_BadStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	es := NewEventStream(config.Endpoint{Name: "test", URL: srv.URL})
+	err := es.Subscribe(t.Context(), []string{"block"}, func() {
+		t.Error("onConnect should not be called on bad status")
+	}, func(Event) {})
+	if err == nil || !strings.Contains(err.Error(), "unexpected status code 503") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEventStream_ContextCancelled_NoError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	es := NewEventStream(config.Endpoint{Name: "test", URL: srv.URL})
+	err := es.Subscribe(ctx, []string{"block"}, cancel, func(Event) {})
+	if err != nil {
+		t.Fatalf("expected nil error on cancelled context, got %v", err)
+	}
+}
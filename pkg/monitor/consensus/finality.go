@@ -0,0 +1,189 @@
+package consensus
+
+import (
+	"context"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/api"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+type FinalityETH2RPC interface {
+	Finality(ctx context.Context, opts *api.FinalityOpts) (*api.Response[*apiv1.Finality], error)
+	SlotDuration(ctx context.Context) (time.Duration, error)
+	SlotsPerEpoch(ctx context.Context) (uint64, error)
+	GenesisTime(ctx context.Context) (time.Time, error)
+}
+
+// finalityMonitor alerts when the chain stops finalizing, either because the finalized
+// checkpoint is too many epochs behind the current epoch or because the justified checkpoint
+// is too far ahead of it.
+type finalityMonitor struct {
+	conf           *config.Config
+	client         FinalityETH2RPC
+	endpoint       config.Endpoint
+	alerts         *alert.Lifecycle
+	epochDuration  time.Duration
+	genesisTime    time.Time
+	finalizedEpoch phase0.Epoch
+	justifiedEpoch phase0.Epoch
+	log            logrus.Ext1FieldLogger
+	tracker        *monitor.Tracker
+}
+
+func NewFinalityMonitor(conf *config.Config, client FinalityETH2RPC, endpoint config.Endpoint, alertChannels []alert.Alert) monitor.Monitor {
+	out := &finalityMonitor{
+		conf:     conf,
+		client:   client,
+		endpoint: endpoint,
+	}
+
+	out.log = conf.Log.WithFields(logrus.Fields{
+		"name":     out.Name(),
+		"endpoint": endpoint.Name,
+	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
+
+	return out
+}
+
+func (fm *finalityMonitor) Name() string {
+	return "consensus::FinalityMonitor::" + fm.endpoint.Name
+}
+
+// fetchChainTiming fetches the epoch duration and genesis time, which the current epoch is computed from.
+func (fm *finalityMonitor) fetchChainTiming(ctx context.Context) error {
+	slotDuration, err := fm.client.SlotDuration(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get slot duration")
+	}
+	slotsPerEpoch, err := fm.client.SlotsPerEpoch(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get slots per epoch")
+	}
+	genesisTime, err := fm.client.GenesisTime(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get genesis time")
+	}
+	fm.epochDuration = slotDuration * time.Duration(slotsPerEpoch)
+	fm.genesisTime = genesisTime
+	return nil
+}
+
+// currentEpoch returns the epoch of the wall clock, 0 before genesis.
+func (fm *finalityMonitor) currentEpoch() phase0.Epoch {
+	if fm.epochDuration == 0 || time.Now().Before(fm.genesisTime) {
+		return 0
+	}
+	return phase0.Epoch(time.Since(fm.genesisTime) / fm.epochDuration)
+}
+
+// stalledEpochs returns the number of epochs the finalized epoch is behind the current epoch, which is 2 on a
+// chain finalizing every epoch. It does not depend on when the monitor started, so that a chain that had
+// already stopped finalizing is reported right away.
+func (fm *finalityMonitor) stalledEpochs() uint64 {
+	current := fm.currentEpoch()
+	if current <= fm.finalizedEpoch {
+		return 0
+	}
+	return uint64(current - fm.finalizedEpoch)
+}
+
+func (fm *finalityMonitor) checkFinality(ctx context.Context) error {
+	if fm.epochDuration == 0 {
+		if err := fm.fetchChainTiming(ctx); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	resp, err := fm.client.Finality(ctx, &api.FinalityOpts{State: "head"})
+	metrics.ObserveRPC(fm.endpoint.Name, "finality_checkpoints", start, err)
+	if err != nil {
+		return errors.Wrap(err, "failed to get finality checkpoints")
+	}
+	if resp == nil || resp.Data == nil || resp.Data.Finalized == nil || resp.Data.Justified == nil {
+		return errors.New("empty finality checkpoints response")
+	}
+
+	finalized, justified := resp.Data.Finalized.Epoch, resp.Data.Justified.Epoch
+	fm.finalizedEpoch = finalized
+	fm.justifiedEpoch = justified
+
+	var gap phase0.Epoch
+	if justified > finalized {
+		gap = justified - finalized
+	}
+	if fm.endpoint.Finality.MaxJustifiedGap > 0 && uint64(gap) > fm.endpoint.Finality.MaxJustifiedGap {
+		return errors.Errorf("justified epoch %d is %d epochs ahead of finalized epoch %d, expected at most %d",
+			justified, gap, finalized, fm.endpoint.Finality.MaxJustifiedGap)
+	}
+
+	if stalled := fm.stalledEpochs(); fm.endpoint.Finality.MaxStalledEpochs > 0 && stalled > fm.endpoint.Finality.MaxStalledEpochs {
+		return errors.Errorf("finalized epoch %d is %d epochs behind the current epoch %d, expected at most %d",
+			finalized, stalled, fm.currentEpoch(), fm.endpoint.Finality.MaxStalledEpochs)
+	}
+	return nil
+}
+
+func (fm *finalityMonitor) Status() monitor.Status {
+	return fm.tracker.Status()
+}
+
+func (fm *finalityMonitor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			fm.log.Info("monitoring stopped")
+			return
+		default:
+			err := fm.checkFinality(ctx)
+			fm.tracker.Record(map[string]any{
+				"finalized_epoch": uint64(fm.finalizedEpoch),
+				"justified_epoch": uint64(fm.justifiedEpoch),
+				"stalled_epochs":  fm.stalledEpochs(),
+			}, err)
+			metrics.FinalizedEpoch.WithLabelValues(fm.endpoint.Name).Set(float64(fm.finalizedEpoch))
+			metrics.JustifiedEpoch.WithLabelValues(fm.endpoint.Name).Set(float64(fm.justifiedEpoch))
+			if err != nil {
+				fm.log.WithError(err).Error("health check failed, raising alert")
+				alertErr := fm.alerts.Trigger(ctx, alert.Message{
+					Message:  err.Error(),
+					Severity: fm.endpoint.Severity.Finality.OrDefault(),
+					Metadata: map[string]any{
+						"finalized_epoch": uint64(fm.finalizedEpoch),
+						"justified_epoch": uint64(fm.justifiedEpoch),
+					},
+				})
+				if alertErr != nil {
+					fm.log.WithError(alertErr).Error("failed to raise alert")
+				}
+			} else {
+				fm.log.WithFields(logrus.Fields{
+					"finalized_epoch": fm.finalizedEpoch,
+					"justified_epoch": fm.justifiedEpoch,
+				}).Info("Endpoint is healthy")
+				if alertErr := fm.alerts.Resolve(ctx); alertErr != nil {
+					fm.log.WithError(alertErr).Error("failed to resolve alert")
+				}
+			}
+		}
+
+		select {
+		case <-time.After(fm.endpoint.PollDuration):
+			continue
+		case <-ctx.Done():
+			fm.log.Info("monitoring stopped")
+			return
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/api"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const testEpochDuration = 384 * time.Second
+
+type fakeFinalityRPC struct {
+	finalized phase0.Epoch
+	justified phase0.Epoch
+	genesis   time.Time
+	err       error
+}
+
+// newFakeFinalityRPC returns a fake whose genesis time puts the current epoch at current.
+func newFakeFinalityRPC(current, finalized, justified phase0.Epoch) *fakeFinalityRPC {
+	genesis := time.Now().Add(-time.Duration(current)*testEpochDuration - testEpochDuration/2)
+	return &fakeFinalityRPC{finalized: finalized, justified: justified, genesis: genesis}
+}
+
+func (f *fakeFinalityRPC) Finality(ctx context.Context, opts *api.FinalityOpts) (*api.Response[*apiv1.Finality], error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &api.Response[*apiv1.Finality]{Data: &apiv1.Finality{
+		Finalized: &phase0.Checkpoint{Epoch: f.finalized},
+		Justified: &phase0.Checkpoint{Epoch: f.justified},
+	}}, nil
+}
+
+func (f *fakeFinalityRPC) SlotDuration(ctx context.Context) (time.Duration, error) {
+	return 12 * time.Second, nil
+}
+
+func (f *fakeFinalityRPC) SlotsPerEpoch(ctx context.Context) (uint64, error) {
+	return 32, nil
+}
+
+func (f *fakeFinalityRPC) GenesisTime(ctx context.Context) (time.Time, error) {
+	return f.genesis, nil
+}
+
+func newTestFinalityMonitor(t *testing.T, rpc FinalityETH2RPC, ep config.Endpoint) *finalityMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	fm, ok := NewFinalityMonitor(conf, rpc, ep, nil).(*finalityMonitor)
+	if !ok {
+		t.Fatal("unexpected monitor type")
+	}
+	return fm
+}
+
+func TestFinality_Healthy(t *testing.T) {
+	rpc := newFakeFinalityRPC(102, 100, 101)
+	fm := newTestFinalityMonitor(t, rpc, config.Endpoint{Finality: config.Finality{MaxStalledEpochs: 4, MaxJustifiedGap: 2}})
+
+	if err := fm.checkFinality(t.Context()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if fm.epochDuration != testEpochDuration {
+		t.Fatalf("unexpected epoch duration %s", fm.epochDuration)
+	}
+	if fm.finalizedEpoch != 100 || fm.justifiedEpoch != 101 {
+		t.Fatalf("unexpected epochs finalized=%d justified=%d", fm.finalizedEpoch, fm.justifiedEpoch)
+	}
+}
+
+func TestFinality_JustifiedGap_Error(t *testing.T) {
+	rpc := newFakeFinalityRPC(106, 100, 105)
+	fm := newTestFinalityMonitor(t, rpc, config.Endpoint{Finality: config.Finality{MaxJustifiedGap: 2}})
+
+	err := fm.checkFinality(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "5 epochs ahead") {
+		t.Fatalf("expected justified gap error, got %v", err)
+	}
+}
+
+func TestFinality_Stalled_Error(t *testing.T) {
+	rpc := newFakeFinalityRPC(110, 100, 101)
+	fm := newTestFinalityMonitor(t, rpc, config.Endpoint{Finality: config.Finality{MaxStalledEpochs: 4}})
+
+	// A chain that stopped finalizing before the monitor started alerts on the first check
+	err := fm.checkFinality(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "10 epochs behind the current epoch 110") {
+		t.Fatalf("expected stalled finality error, got %v", err)
+	}
+}
+
+func TestFinality_AdvanceResetsStall(t *testing.T) {
+	rpc := newFakeFinalityRPC(110, 100, 101)
+	fm := newTestFinalityMonitor(t, rpc, config.Endpoint{Finality: config.Finality{MaxStalledEpochs: 4}})
+
+	if err := fm.checkFinality(t.Context()); err == nil {
+		t.Fatal("expected stalled finality error")
+	}
+	rpc.finalized, rpc.justified = 108, 109
+
+	if err := fm.checkFinality(t.Context()); err != nil {
+		t.Fatalf("expected finalized epoch advance to reset stall, got %v", err)
+	}
+}
+
+func TestFinality_BeforeGenesis(t *testing.T) {
+	rpc := &fakeFinalityRPC{genesis: time.Now().Add(time.Hour)}
+	fm := newTestFinalityMonitor(t, rpc, config.Endpoint{Finality: config.Finality{MaxStalledEpochs: 4}})
+
+	if err := fm.checkFinality(t.Context()); err != nil {
+		t.Fatalf("expected no error before genesis, got %v", err)
+	}
+}
+
+func TestFinality_RPCError_Propagates(t *testing.T) {
+	rpc := &fakeFinalityRPC{err: errors.New("boom")}
+	fm := newTestFinalityMonitor(t, rpc, config.Endpoint{Finality: config.Finality{MaxStalledEpochs: 4}})
+
+	err := fm.checkFinality(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "failed to get finality checkpoints") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/auth"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/monitor/generic"
+)
+
+// genesisClient queries the genesis on every call, unlike the go-eth2-client which caches it for
+// the lifetime of the client and would not notice the endpoint switching to another chain.
+type genesisClient struct {
+	endpoint config.Endpoint
+	client   *http.Client
+}
+
+func (gc *genesisClient) ChainIdentity(ctx context.Context) (map[string]string, error) {
+	reqURL, err := url.JoinPath(gc.endpoint.URL, "/eth/v1/beacon/genesis")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create genesis URL for endpoint %s", gc.endpoint.Name)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create genesis request for endpoint %s", gc.endpoint.Name)
+	}
+
+	start := time.Now()
+	resp, err := gc.client.Do(req)
+	metrics.ObserveRPC(gc.endpoint.Name, "beacon_genesis", start, err)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to perform genesis request for endpoint %s", gc.endpoint.Name)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d from genesis request for endpoint %s", resp.StatusCode, gc.endpoint.Name)
+	}
+
+	var result struct {
+		Data struct {
+			GenesisValidatorsRoot string `json:"genesis_validators_root"`
+			GenesisForkVersion    string `json:"genesis_fork_version"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode genesis response for endpoint %s", gc.endpoint.Name)
+	}
+	return map[string]string{
+		"genesis_validators_root": result.Data.GenesisValidatorsRoot,
+		"genesis_fork_version":    result.Data.GenesisForkVersion,
+	}, nil
+}
+
+func NewGenesisClient(endpoint config.Endpoint) generic.RPCChainIdentity {
+	return &genesisClient{
+		endpoint: endpoint,
+		client:   auth.NewClient(endpoint, 10*time.Second),
+	}
+}
+
+// NewChainMonitor returns a monitor alerting when the genesis of the endpoint differs from the configured one.
+func NewChainMonitor(conf *config.Config, alertChannels []alert.Alert, endpoint config.Endpoint) monitor.Monitor {
+	expected := map[string]string{}
+	if endpoint.Genesis.ValidatorsRoot != "" {
+		expected["genesis_validators_root"] = endpoint.Genesis.ValidatorsRoot
+	}
+	if endpoint.Genesis.ForkVersion != "" {
+		expected["genesis_fork_version"] = endpoint.Genesis.ForkVersion
+	}
+	return generic.NewChainMonitor(conf, alertChannels, NewGenesisClient(endpoint), endpoint, config.TypeConsensus, expected)
+}
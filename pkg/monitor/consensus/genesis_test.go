@@ -0,0 +1,39 @@
+package consensus
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func TestGenesisClient_ChainIdentity(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/eth/v1/beacon/genesis" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		requests++
+		fmt.Fprint(w, `{"data":{"genesis_time":"1606824023","genesis_validators_root":"0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95","genesis_fork_version":"0x00000000"}}`)
+	}))
+	defer srv.Close()
+
+	client := NewGenesisClient(config.Endpoint{Name: "test", URL: srv.URL})
+	for range 2 {
+		identity, err := client.ChainIdentity(t.Context())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := identity["genesis_validators_root"], "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95"; got != want {
+			t.Fatalf("unexpected validators root got %q want %q", got, want)
+		}
+		if got, want := identity["genesis_fork_version"], "0x00000000"; got != want {
+			t.Fatalf("unexpected fork version got %q want %q", got, want)
+		}
+	}
+	if requests != 2 {
+		t.Fatalf("expected the genesis to be requested on every check, got %d requests", requests)
+	}
+}
@@ -0,0 +1,69 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/auth"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor/generic"
+)
+
+type headClient struct {
+	endpoint config.Endpoint
+	client   *http.Client
+}
+
+// Head returns the slot of the endpoint's head block.
+func (hc *headClient) Head(ctx context.Context) (uint64, error) {
+	reqURL, err := url.JoinPath(hc.endpoint.URL, "/eth/v1/beacon/headers/head")
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create head URL for endpoint %s", hc.endpoint.Name)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create head request for endpoint %s", hc.endpoint.Name)
+	}
+
+	resp, err := hc.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to perform head request for endpoint %s", hc.endpoint.Name)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.Errorf("unexpected status code %d from head request for endpoint %s", resp.StatusCode, hc.endpoint.Name)
+	}
+
+	var result struct {
+		Data struct {
+			Header struct {
+				Message struct {
+					Slot string `json:"slot"`
+				} `json:"message"`
+			} `json:"header"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, errors.Wrapf(err, "failed to decode head response for endpoint %s", hc.endpoint.Name)
+	}
+
+	slot, err := strconv.ParseUint(result.Data.Header.Message.Slot, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse head slot for endpoint %s", hc.endpoint.Name)
+	}
+	return slot, nil
+}
+
+func NewHeadClient(endpoint config.Endpoint) generic.RPCHead {
+	return &headClient{
+		endpoint: endpoint,
+		client:   auth.NewClient(endpoint, 10*time.Second),
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/cockroachdb/errors"
 
 	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/auth"
 	"github.com/numbergroup/eth-monitor/pkg/config"
 	"github.com/numbergroup/eth-monitor/pkg/monitor"
 	"github.com/numbergroup/eth-monitor/pkg/monitor/generic"
@@ -64,9 +65,7 @@ func (pc *peerCount) PeerCount(ctx context.Context) (uint64, error) {
 func NewPeerCountClient(endpoint config.Endpoint) generic.RPCPeerCount {
 	return &peerCount{
 		endpoint: endpoint,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client:   auth.NewClient(endpoint, 10*time.Second),
 	}
 }
 
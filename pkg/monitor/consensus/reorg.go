@@ -0,0 +1,204 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/backoff"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+// reorgMonitor listens to the chain_reorg events of a beacon node, and alerts when a reorg
+// deeper than the configured maximum was reported since the previous check.
+type reorgMonitor struct {
+	conf     *config.Config
+	client   EventSubscriber
+	endpoint config.Endpoint
+	alerts   *alert.Lifecycle
+	log      logrus.Ext1FieldLogger
+	tracker  *monitor.Tracker
+
+	mu        sync.Mutex
+	connected bool
+	lastReorg *apiv1.ChainReorgEvent
+	deepest   *apiv1.ChainReorgEvent // Deepest reorg above the maximum since the previous check
+}
+
+func NewReorgMonitor(conf *config.Config, client EventSubscriber, endpoint config.Endpoint, alertChannels []alert.Alert) monitor.Monitor {
+	out := &reorgMonitor{
+		conf:     conf,
+		client:   client,
+		endpoint: endpoint,
+	}
+
+	out.log = conf.Log.WithFields(logrus.Fields{
+		"name":     out.Name(),
+		"endpoint": endpoint.Name,
+	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
+
+	return out
+}
+
+func (rm *reorgMonitor) Name() string {
+	return "consensus::ReorgMonitor::" + rm.endpoint.Name
+}
+
+func (rm *reorgMonitor) onReorg(event Event) {
+	reorg := &apiv1.ChainReorgEvent{}
+	if err := json.Unmarshal(event.Data, reorg); err != nil {
+		rm.log.WithError(err).WithField("topic", event.Topic).Warn("failed to decode chain reorg event")
+		return
+	}
+	rm.log.WithFields(logrus.Fields{
+		"slot":     reorg.Slot,
+		"depth":    reorg.Depth,
+		"old_head": reorg.OldHeadBlock,
+		"new_head": reorg.NewHeadBlock,
+	}).Warn("chain reorganization detected")
+	metrics.Reorgs.WithLabelValues(rm.endpoint.Name, config.TypeConsensus).Inc()
+	metrics.ReorgDepth.WithLabelValues(rm.endpoint.Name, config.TypeConsensus).Set(float64(reorg.Depth))
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.lastReorg = reorg
+	if reorg.Depth > rm.endpoint.Reorg.MaxDepth && (rm.deepest == nil || reorg.Depth > rm.deepest.Depth) {
+		rm.deepest = reorg
+	}
+}
+
+func (rm *reorgMonitor) setConnected(connected bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.connected = connected
+}
+
+// listen keeps the events stream subscribed, reconnecting with exponential backoff until ctx is cancelled.
+// Disconnects are only logged, the block monitor alerts on the stream being down.
+func (rm *reorgMonitor) listen(ctx context.Context) {
+	retry := backoff.New(minReconnectDelay, maxReconnectDelay)
+	for {
+		err := rm.client.Subscribe(ctx, []string{"chain_reorg"}, func() {
+			rm.setConnected(true)
+			rm.log.Info("connected to events stream")
+		}, rm.onReorg)
+		if ctx.Err() != nil {
+			return
+		}
+
+		rm.mu.Lock()
+		wasConnected := rm.connected
+		rm.connected = false
+		rm.mu.Unlock()
+		if wasConnected {
+			retry.Reset()
+		}
+
+		delay := retry.Next()
+		rm.log.WithError(err).WithFields(logrus.Fields{
+			"attempt": retry.Attempt(),
+			"delay":   delay,
+		}).Warn("chain reorg event stream disconnected, reconnecting")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkReorgs returns the deepest reorg above the maximum reported since the previous check, and an error describing it.
+func (rm *reorgMonitor) checkReorgs() (*apiv1.ChainReorgEvent, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	deepest := rm.deepest
+	rm.deepest = nil
+	if deepest == nil {
+		return nil, nil
+	}
+	return deepest, fmt.Errorf("reorg of depth %d at slot %d replaced head %s with %s, expected at most %d",
+		deepest.Depth, deepest.Slot, deepest.OldHeadBlock, deepest.NewHeadBlock, rm.endpoint.Reorg.MaxDepth)
+}
+
+func (rm *reorgMonitor) Status() monitor.Status {
+	return rm.tracker.Status()
+}
+
+// Run alerts when a reorg deeper than the maximum was reported since the previous check, and resolves at the next check without one.
+func (rm *reorgMonitor) Run(ctx context.Context) {
+	// The event stream stops with ctx, Run returns once it has
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rm.listen(ctx)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			rm.log.Info("monitoring stopped")
+			return
+		default:
+			event, err := rm.checkReorgs()
+
+			rm.mu.Lock()
+			status := map[string]any{
+				"stream_connected": rm.connected,
+			}
+			if rm.lastReorg != nil {
+				status["last_reorg"] = map[string]any{
+					"slot":     uint64(rm.lastReorg.Slot),
+					"depth":    rm.lastReorg.Depth,
+					"old_head": rm.lastReorg.OldHeadBlock.String(),
+					"new_head": rm.lastReorg.NewHeadBlock.String(),
+				}
+			}
+			rm.mu.Unlock()
+
+			rm.tracker.Record(status, err)
+			if err != nil {
+				rm.log.WithError(err).Error("health check failed, raising alert")
+				alertErr := rm.alerts.Trigger(ctx, alert.Message{
+					Message:  err.Error(),
+					Severity: rm.endpoint.Severity.Reorg.OrDefault(),
+					Metadata: map[string]any{
+						"slot":     uint64(event.Slot),
+						"depth":    event.Depth,
+						"old_head": event.OldHeadBlock.String(),
+						"new_head": event.NewHeadBlock.String(),
+					},
+				})
+				if alertErr != nil {
+					rm.log.WithError(alertErr).Error("failed to raise alert")
+				}
+			} else {
+				rm.log.Info("Endpoint is healthy")
+				if alertErr := rm.alerts.Resolve(ctx); alertErr != nil {
+					rm.log.WithError(alertErr).Error("failed to resolve alert")
+				}
+			}
+		}
+
+		select {
+		case <-time.After(rm.endpoint.PollDuration):
+			continue
+		case <-ctx.Done():
+			rm.log.Info("monitoring stopped")
+			return
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package consensus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func reorgEvent(slot, depth string) Event {
+	return Event{Topic: "chain_reorg", Data: []byte(`{"slot":"` + slot + `","depth":"` + depth + `",` +
+		`"old_head_block":"0x0000000000000000000000000000000000000000000000000000000000000001",` +
+		`"new_head_block":"0x0000000000000000000000000000000000000000000000000000000000000002",` +
+		`"old_head_state":"0x0000000000000000000000000000000000000000000000000000000000000003",` +
+		`"new_head_state":"0x0000000000000000000000000000000000000000000000000000000000000004",` +
+		`"epoch":"1","execution_optimistic":false}`)}
+}
+
+func newTestReorgMonitor(t *testing.T, sub EventSubscriber, maxDepth uint64) *reorgMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	rm, ok := NewReorgMonitor(conf, sub, config.Endpoint{Reorg: config.Reorg{MaxDepth: maxDepth}}, nil).(*reorgMonitor)
+	if !ok {
+		t.Fatal("unexpected monitor type")
+	}
+	return rm
+}
+
+func TestReorgMonitor_ShallowReorg_OK(t *testing.T) {
+	sub := &fakeSubscriber{events: []Event{reorgEvent("40", "1")}}
+	rm := newTestReorgMonitor(t, sub, 2)
+
+	_ = sub.Subscribe(t.Context(), nil, func() {}, rm.onReorg)
+	if rm.lastReorg == nil || rm.lastReorg.Depth != 1 {
+		t.Fatalf("expected reorg to be recorded, got %+v", rm.lastReorg)
+	}
+	if _, err := rm.checkReorgs(); err != nil {
+		t.Fatalf("expected reorg within max depth to not error, got %v", err)
+	}
+}
+
+func TestReorgMonitor_DeepReorg_Error(t *testing.T) {
+	sub := &fakeSubscriber{events: []Event{reorgEvent("40", "3"), reorgEvent("41", "5"), reorgEvent("42", "1")}}
+	rm := newTestReorgMonitor(t, sub, 2)
+
+	_ = sub.Subscribe(t.Context(), nil, func() {}, rm.onReorg)
+	event, err := rm.checkReorgs()
+	if err == nil || !strings.Contains(err.Error(), "reorg of depth 5 at slot 41") {
+		t.Fatalf("expected deepest reorg error, got %v", err)
+	}
+	if event.Depth != 5 {
+		t.Fatalf("expected deepest reorg, got %+v", event)
+	}
+
+	// Each check reports the reorgs since the previous one
+	if _, err := rm.checkReorgs(); err != nil {
+		t.Fatalf("expected no error without new reorgs, got %v", err)
+	}
+}
@@ -8,11 +8,16 @@ import (
 	"github.com/cockroachdb/errors"
 
 	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/auth"
 	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
 )
 
-func RunMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []alert.Alert) error {
-	client, err := http.New(ctx, http.WithAddress(endpoint.URL))
+func RunMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []alert.Alert, registry *monitor.Registry) error {
+	client, err := http.New(ctx,
+		http.WithAddress(endpoint.URL),
+		http.WithHTTPClient(auth.NewClient(endpoint, 0)),
+	)
 	if err != nil {
 		return errors.Wrap(err, "failed to create HTTP client")
 	}
@@ -21,25 +26,83 @@ func RunMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Co
 	if endpoint.NewBlockMaxDuration > 0 {
 		waitGroup.Add(1)
 		go func() {
-			mon := NewBlockMonitor(conf, httpClient, endpoint, alertChannels)
+			mon := NewBlockMonitor(conf, NewEventStream(endpoint), endpoint, alertChannels)
 			conf.Log.WithField("name", mon.Name()).Info("block monitoring started")
 
 			defer waitGroup.Done()
+			registry.Register(mon)
+			defer registry.Unregister(mon)
 			mon.Run(ctx)
 		}()
 	}
 
-	if endpoint.MinPeers > 0 {
+	if endpoint.PeerCountEnabled() {
+		mon, err := NewPeerCountMonitor(conf, alertChannels, endpoint)
+		if err != nil {
+			return errors.Wrap(err, "failed to create peer count monitor")
+		}
 		waitGroup.Add(1)
 		go func() {
-			mon, err := NewPeerCountMonitor(conf, alertChannels, endpoint)
-			if err != nil {
-				conf.Log.WithError(err).WithField("endpoint", endpoint.Name).Error("failed to create peer count monitor")
-				return
-			}
 			conf.Log.WithField("name", mon.Name()).Info("peer count monitoring started")
 
 			defer waitGroup.Done()
+			registry.Register(mon)
+			defer registry.Unregister(mon)
+			mon.Run(ctx)
+		}()
+	}
+
+	if endpoint.ChainCheckEnabled() {
+		waitGroup.Add(1)
+		go func() {
+			mon := NewChainMonitor(conf, alertChannels, endpoint)
+			conf.Log.WithField("name", mon.Name()).Info("chain monitoring started")
+
+			defer waitGroup.Done()
+			registry.Register(mon)
+			defer registry.Unregister(mon)
+			mon.Run(ctx)
+		}()
+	}
+
+	if endpoint.Reorg.Enabled() {
+		waitGroup.Add(1)
+		go func() {
+			mon := NewReorgMonitor(conf, NewEventStream(endpoint), endpoint, alertChannels)
+			conf.Log.WithField("name", mon.Name()).Info("reorg monitoring started")
+
+			defer waitGroup.Done()
+			registry.Register(mon)
+			defer registry.Unregister(mon)
+			mon.Run(ctx)
+		}()
+	}
+
+	if endpoint.Finality.Enabled() {
+		waitGroup.Add(1)
+		go func() {
+			mon := NewFinalityMonitor(conf, httpClient, endpoint, alertChannels)
+			conf.Log.WithField("name", mon.Name()).Info("finality monitoring started")
+
+			defer waitGroup.Done()
+			registry.Register(mon)
+			defer registry.Unregister(mon)
+			mon.Run(ctx)
+		}()
+	}
+
+	if endpoint.Validators.Enabled() {
+		mon, err := NewValidatorMonitor(conf, httpClient, endpoint, alertChannels)
+		if err != nil {
+			return errors.Wrap(err, "failed to create validator monitor")
+		}
+		waitGroup.Add(1)
+		go func() {
+			conf.Log.WithField("name", mon.Name()).Info("validator monitoring started")
+
+			defer waitGroup.Done()
+			registry.Register(mon)
+			defer registry.Unregister(mon)
 			mon.Run(ctx)
 		}()
 	}
@@ -0,0 +1,313 @@
+package consensus
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/api"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+type ValidatorETH2RPC interface {
+	Validators(ctx context.Context, opts *api.ValidatorsOpts) (*api.Response[map[phase0.ValidatorIndex]*apiv1.Validator], error)
+	ValidatorLiveness(ctx context.Context, opts *api.ValidatorLivenessOpts) (*api.Response[[]*apiv1.ValidatorLiveness], error)
+	ProposerDuties(ctx context.Context, opts *api.ProposerDutiesOpts) (*api.Response[[]*apiv1.ProposerDuty], error)
+	BeaconBlockHeader(ctx context.Context, opts *api.BeaconBlockHeaderOpts) (*api.Response[*apiv1.BeaconBlockHeader], error)
+	SlotsPerEpoch(ctx context.Context) (uint64, error)
+}
+
+// validatorMonitor checks the configured validators once per epoch for their status,
+// balance changes, missed attestations, missed block proposals and slashing.
+type validatorMonitor struct {
+	conf          *config.Config
+	client        ValidatorETH2RPC
+	endpoint      config.Endpoint
+	alerts        *alert.Lifecycle
+	indices       []phase0.ValidatorIndex
+	pubKeys       []phase0.BLSPubKey
+	slotsPerEpoch uint64
+	checkedEpoch  phase0.Epoch
+	hasChecked    bool
+	balances      map[phase0.ValidatorIndex]phase0.Gwei
+	log           logrus.Ext1FieldLogger
+	tracker       *monitor.Tracker
+}
+
+func NewValidatorMonitor(conf *config.Config, client ValidatorETH2RPC, endpoint config.Endpoint, alertChannels []alert.Alert) (monitor.Monitor, error) {
+	out := &validatorMonitor{
+		conf:     conf,
+		client:   client,
+		endpoint: endpoint,
+		balances: map[phase0.ValidatorIndex]phase0.Gwei{},
+	}
+
+	for _, id := range endpoint.Validators.IDs {
+		if strings.HasPrefix(id, "0x") {
+			data, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+			if err != nil || len(data) != len(phase0.BLSPubKey{}) {
+				return nil, errors.Errorf("invalid validator public key %q for endpoint %s", id, endpoint.Name)
+			}
+			out.pubKeys = append(out.pubKeys, phase0.BLSPubKey(data))
+			continue
+		}
+		index, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid validator index %q for endpoint %s", id, endpoint.Name)
+		}
+		out.indices = append(out.indices, phase0.ValidatorIndex(index))
+	}
+
+	out.log = conf.Log.WithFields(logrus.Fields{
+		"name":     out.Name(),
+		"endpoint": endpoint.Name,
+	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
+
+	return out, nil
+}
+
+func (vm *validatorMonitor) Name() string {
+	return "consensus::ValidatorMonitor::" + vm.endpoint.Name
+}
+
+// headSlot returns the slot of the current head block.
+func (vm *validatorMonitor) headSlot(ctx context.Context) (phase0.Slot, error) {
+	start := time.Now()
+	resp, err := vm.client.BeaconBlockHeader(ctx, &api.BeaconBlockHeaderOpts{Block: "head"})
+	metrics.ObserveRPC(vm.endpoint.Name, "beacon_headers", start, err)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get head block header")
+	}
+	if resp == nil || resp.Data == nil || resp.Data.Header == nil || resp.Data.Header.Message == nil {
+		return 0, errors.New("empty head block header response")
+	}
+	return resp.Data.Header.Message.Slot, nil
+}
+
+// blockExists returns false if no block was proposed at the slot.
+func (vm *validatorMonitor) blockExists(ctx context.Context, slot phase0.Slot) (bool, error) {
+	start := time.Now()
+	_, err := vm.client.BeaconBlockHeader(ctx, &api.BeaconBlockHeaderOpts{Block: strconv.FormatUint(uint64(slot), 10)})
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		metrics.ObserveRPC(vm.endpoint.Name, "beacon_headers", start, nil)
+		return false, nil
+	}
+	metrics.ObserveRPC(vm.endpoint.Name, "beacon_headers", start, err)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get block header for slot %d", slot)
+	}
+	return true, nil
+}
+
+// checkStatus checks the status, balance and slashing of the validators and returns
+// their indices, resolving configured public keys.
+func (vm *validatorMonitor) checkStatus(ctx context.Context) ([]phase0.ValidatorIndex, []string, error) {
+	start := time.Now()
+	resp, err := vm.client.Validators(ctx, &api.ValidatorsOpts{
+		State:   "head",
+		Indices: vm.indices,
+		PubKeys: vm.pubKeys,
+	})
+	metrics.ObserveRPC(vm.endpoint.Name, "validators", start, err)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get validators")
+	}
+
+	var (
+		indices  []phase0.ValidatorIndex
+		problems []string
+		settings = vm.endpoint.Validators
+	)
+	for index, validator := range resp.Data {
+		indices = append(indices, index)
+		label := strconv.FormatUint(uint64(index), 10)
+		metrics.ValidatorBalance.WithLabelValues(vm.endpoint.Name, label).Set(float64(validator.Balance))
+
+		if validator.Validator != nil && validator.Validator.Slashed {
+			problems = append(problems, fmt.Sprintf("validator %d has been slashed", index))
+		}
+		if settings.AlertOnInactive && !validator.Status.IsActive() {
+			problems = append(problems, fmt.Sprintf("validator %d is %s", index, validator.Status))
+		}
+		if previous, ok := vm.balances[index]; ok && settings.MaxBalanceDecrease > 0 && previous > validator.Balance {
+			if decrease := uint64(previous - validator.Balance); decrease > settings.MaxBalanceDecrease {
+				problems = append(problems, fmt.Sprintf("validator %d balance decreased by %d gwei, expected at most %d", index, decrease, settings.MaxBalanceDecrease))
+			}
+		}
+		vm.balances[index] = validator.Balance
+	}
+	slices.Sort(indices)
+
+	if configured := len(vm.indices) + len(vm.pubKeys); len(indices) < configured {
+		problems = append(problems, fmt.Sprintf("only %d of %d configured validators found", len(indices), configured))
+	}
+	return indices, problems, nil
+}
+
+// checkAttestations returns the validators that were not seen attesting in the epoch.
+func (vm *validatorMonitor) checkAttestations(ctx context.Context, epoch phase0.Epoch, indices []phase0.ValidatorIndex) ([]phase0.ValidatorIndex, error) {
+	start := time.Now()
+	resp, err := vm.client.ValidatorLiveness(ctx, &api.ValidatorLivenessOpts{Epoch: epoch, Indices: indices})
+	metrics.ObserveRPC(vm.endpoint.Name, "validator_liveness", start, err)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get validator liveness for epoch %d", epoch)
+	}
+
+	var missed []phase0.ValidatorIndex
+	for _, liveness := range resp.Data {
+		if !liveness.IsLive {
+			missed = append(missed, liveness.Index)
+		}
+	}
+	return missed, nil
+}
+
+// checkProposals returns the slots up to headSlot in which one of the validators was due to propose but no block exists.
+func (vm *validatorMonitor) checkProposals(ctx context.Context, epoch phase0.Epoch, headSlot phase0.Slot, indices []phase0.ValidatorIndex) ([]phase0.Slot, error) {
+	start := time.Now()
+	resp, err := vm.client.ProposerDuties(ctx, &api.ProposerDutiesOpts{Epoch: epoch, Indices: indices})
+	metrics.ObserveRPC(vm.endpoint.Name, "proposer_duties", start, err)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get proposer duties for epoch %d", epoch)
+	}
+
+	var missed []phase0.Slot
+	for _, duty := range resp.Data {
+		if !slices.Contains(indices, duty.ValidatorIndex) || duty.Slot > headSlot {
+			continue
+		}
+		exists, err := vm.blockExists(ctx, duty.Slot)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missed = append(missed, duty.Slot)
+		}
+	}
+	return missed, nil
+}
+
+// checkValidators evaluates the last completed epoch, returning false if it was already evaluated.
+func (vm *validatorMonitor) checkValidators(ctx context.Context) (bool, error) {
+	if vm.slotsPerEpoch == 0 {
+		slotsPerEpoch, err := vm.client.SlotsPerEpoch(ctx)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get slots per epoch")
+		}
+		vm.slotsPerEpoch = slotsPerEpoch
+	}
+
+	headSlot, err := vm.headSlot(ctx)
+	if err != nil {
+		return false, err
+	}
+	currentEpoch := phase0.Epoch(uint64(headSlot) / vm.slotsPerEpoch)
+	if currentEpoch == 0 {
+		return false, nil
+	}
+	epoch := currentEpoch - 1
+	if vm.hasChecked && epoch <= vm.checkedEpoch {
+		return false, nil
+	}
+
+	indices, problems, err := vm.checkStatus(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	settings := vm.endpoint.Validators
+	if len(indices) > 0 {
+		missedAttestations, err := vm.checkAttestations(ctx, epoch, indices)
+		if err != nil {
+			return false, err
+		}
+		metrics.ValidatorMissedAttestations.WithLabelValues(vm.endpoint.Name).Add(float64(len(missedAttestations)))
+		if len(missedAttestations) > settings.MaxMissedAttestations {
+			problems = append(problems, fmt.Sprintf("%d validators missed attestations %v, expected at most %d", len(missedAttestations), missedAttestations, settings.MaxMissedAttestations))
+		}
+
+		missedProposals, err := vm.checkProposals(ctx, epoch, headSlot, indices)
+		if err != nil {
+			return false, err
+		}
+		metrics.ValidatorMissedProposals.WithLabelValues(vm.endpoint.Name).Add(float64(len(missedProposals)))
+		if len(missedProposals) > settings.MaxMissedProposals {
+			problems = append(problems, fmt.Sprintf("%d block proposals missed in slots %v, expected at most %d", len(missedProposals), missedProposals, settings.MaxMissedProposals))
+		}
+	}
+
+	vm.checkedEpoch = epoch
+	vm.hasChecked = true
+	if len(problems) > 0 {
+		return true, errors.Errorf("validator problems in epoch %d: %s", epoch, strings.Join(problems, "; "))
+	}
+	return true, nil
+}
+
+func (vm *validatorMonitor) Status() monitor.Status {
+	return vm.tracker.Status()
+}
+
+func (vm *validatorMonitor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			vm.log.Info("monitoring stopped")
+			return
+		default:
+			checked, err := vm.checkValidators(ctx)
+			if err != nil || checked {
+				vm.tracker.Record(map[string]any{
+					"epoch":      uint64(vm.checkedEpoch),
+					"validators": len(vm.balances),
+				}, err)
+			}
+			if err != nil {
+				vm.log.WithError(err).Error("health check failed, raising alert")
+				alertErr := vm.alerts.Trigger(ctx, alert.Message{
+					Message:  err.Error(),
+					Severity: vm.endpoint.Severity.Validators.OrDefault(),
+					Metadata: map[string]any{
+						"epoch":      uint64(vm.checkedEpoch),
+						"validators": len(vm.endpoint.Validators.IDs),
+					},
+				})
+				if alertErr != nil {
+					vm.log.WithError(alertErr).Error("failed to raise alert")
+				}
+			} else if checked {
+				vm.log.WithFields(logrus.Fields{
+					"epoch":      vm.checkedEpoch,
+					"validators": len(vm.balances),
+				}).Info("Validators are healthy")
+				if alertErr := vm.alerts.Resolve(ctx); alertErr != nil {
+					vm.log.WithError(alertErr).Error("failed to resolve alert")
+				}
+			}
+		}
+
+		select {
+		case <-time.After(vm.endpoint.PollDuration):
+			continue
+		case <-ctx.Done():
+			vm.log.Info("monitoring stopped")
+			return
+		}
+	}
+}
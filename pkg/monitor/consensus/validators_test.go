@@ -0,0 +1,206 @@
+package consensus
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/api"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+type fakeValidatorRPC struct {
+	headSlot   phase0.Slot
+	validators map[phase0.ValidatorIndex]*apiv1.Validator
+	notLive    []phase0.ValidatorIndex
+	duties     []*apiv1.ProposerDuty
+	emptySlots []phase0.Slot
+}
+
+func (f *fakeValidatorRPC) Validators(ctx context.Context, opts *api.ValidatorsOpts) (*api.Response[map[phase0.ValidatorIndex]*apiv1.Validator], error) {
+	return &api.Response[map[phase0.ValidatorIndex]*apiv1.Validator]{Data: f.validators}, nil
+}
+
+func (f *fakeValidatorRPC) ValidatorLiveness(ctx context.Context, opts *api.ValidatorLivenessOpts) (*api.Response[[]*apiv1.ValidatorLiveness], error) {
+	var out []*apiv1.ValidatorLiveness
+	for _, index := range opts.Indices {
+		live := true
+		for _, missed := range f.notLive {
+			if missed == index {
+				live = false
+			}
+		}
+		out = append(out, &apiv1.ValidatorLiveness{Index: index, IsLive: live})
+	}
+	return &api.Response[[]*apiv1.ValidatorLiveness]{Data: out}, nil
+}
+
+func (f *fakeValidatorRPC) ProposerDuties(ctx context.Context, opts *api.ProposerDutiesOpts) (*api.Response[[]*apiv1.ProposerDuty], error) {
+	return &api.Response[[]*apiv1.ProposerDuty]{Data: f.duties}, nil
+}
+
+func (f *fakeValidatorRPC) BeaconBlockHeader(ctx context.Context, opts *api.BeaconBlockHeaderOpts) (*api.Response[*apiv1.BeaconBlockHeader], error) {
+	slot := f.headSlot
+	if opts.Block != "head" {
+		parsed, _ := strconv.ParseUint(opts.Block, 10, 64)
+		slot = phase0.Slot(parsed)
+		for _, empty := range f.emptySlots {
+			if empty == slot {
+				return nil, &api.Error{StatusCode: http.StatusNotFound}
+			}
+		}
+	}
+	return &api.Response[*apiv1.BeaconBlockHeader]{Data: &apiv1.BeaconBlockHeader{
+		Header: &phase0.SignedBeaconBlockHeader{Message: &phase0.BeaconBlockHeader{Slot: slot}},
+	}}, nil
+}
+
+func (f *fakeValidatorRPC) SlotsPerEpoch(ctx context.Context) (uint64, error) {
+	return 32, nil
+}
+
+func activeValidator(index phase0.ValidatorIndex, balance phase0.Gwei) *apiv1.Validator {
+	return &apiv1.Validator{
+		Index:     index,
+		Balance:   balance,
+		Status:    apiv1.ValidatorStateActiveOngoing,
+		Validator: &phase0.Validator{},
+	}
+}
+
+func newTestValidatorMonitor(t *testing.T, rpc ValidatorETH2RPC, settings config.Validators) *validatorMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	mon, err := NewValidatorMonitor(conf, rpc, config.Endpoint{Validators: settings}, nil)
+	if err != nil {
+		t.Fatalf("failed to create monitor: %v", err)
+	}
+	vm, ok := mon.(*validatorMonitor)
+	if !ok {
+		t.Fatal("unexpected monitor type")
+	}
+	return vm
+}
+
+func TestValidators_Healthy(t *testing.T) {
+	rpc := &fakeValidatorRPC{
+		headSlot: 100,
+		validators: map[phase0.ValidatorIndex]*apiv1.Validator{
+			1: activeValidator(1, 32e9),
+			2: activeValidator(2, 32e9),
+		},
+		duties: []*apiv1.ProposerDuty{{ValidatorIndex: 1, Slot: 70}},
+	}
+	vm := newTestValidatorMonitor(t, rpc, config.Validators{IDs: []string{"1", "2"}})
+
+	checked, err := vm.checkValidators(t.Context())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !checked || vm.checkedEpoch != 2 {
+		t.Fatalf("expected epoch 2 to be checked, got checked=%v epoch=%d", checked, vm.checkedEpoch)
+	}
+
+	// The same epoch is not evaluated twice
+	checked, err = vm.checkValidators(t.Context())
+	if err != nil || checked {
+		t.Fatalf("expected epoch to be skipped, got checked=%v err=%v", checked, err)
+	}
+}
+
+func TestValidators_MissedAttestationsAndProposals(t *testing.T) {
+	rpc := &fakeValidatorRPC{
+		headSlot: 100,
+		validators: map[phase0.ValidatorIndex]*apiv1.Validator{
+			1: activeValidator(1, 32e9),
+			2: activeValidator(2, 32e9),
+		},
+		notLive:    []phase0.ValidatorIndex{2},
+		duties:     []*apiv1.ProposerDuty{{ValidatorIndex: 1, Slot: 70}},
+		emptySlots: []phase0.Slot{70},
+	}
+	vm := newTestValidatorMonitor(t, rpc, config.Validators{IDs: []string{"1", "2"}})
+
+	_, err := vm.checkValidators(t.Context())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "1 validators missed attestations [2]") {
+		t.Fatalf("expected missed attestation in error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 block proposals missed in slots [70]") {
+		t.Fatalf("expected missed proposal in error, got %v", err)
+	}
+}
+
+func TestValidators_MissedAttestationsWithinThreshold_OK(t *testing.T) {
+	rpc := &fakeValidatorRPC{
+		headSlot: 100,
+		validators: map[phase0.ValidatorIndex]*apiv1.Validator{
+			1: activeValidator(1, 32e9),
+		},
+		notLive: []phase0.ValidatorIndex{1},
+	}
+	vm := newTestValidatorMonitor(t, rpc, config.Validators{IDs: []string{"1"}, MaxMissedAttestations: 1})
+
+	if _, err := vm.checkValidators(t.Context()); err != nil {
+		t.Fatalf("expected no error within threshold, got %v", err)
+	}
+}
+
+func TestValidators_SlashedAndBalanceDecrease(t *testing.T) {
+	rpc := &fakeValidatorRPC{
+		headSlot: 100,
+		validators: map[phase0.ValidatorIndex]*apiv1.Validator{
+			1: activeValidator(1, 32e9),
+		},
+	}
+	vm := newTestValidatorMonitor(t, rpc, config.Validators{IDs: []string{"1"}, MaxBalanceDecrease: 1000})
+
+	if _, err := vm.checkValidators(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rpc.headSlot = 140
+	slashed := activeValidator(1, 32e9-1e6)
+	slashed.Validator.Slashed = true
+	rpc.validators[1] = slashed
+
+	_, err := vm.checkValidators(t.Context())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "validator 1 has been slashed") {
+		t.Fatalf("expected slashing in error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "balance decreased by 1000000 gwei") {
+		t.Fatalf("expected balance decrease in error, got %v", err)
+	}
+}
+
+func TestValidators_MissingValidator(t *testing.T) {
+	rpc := &fakeValidatorRPC{
+		headSlot:   100,
+		validators: map[phase0.ValidatorIndex]*apiv1.Validator{1: activeValidator(1, 32e9)},
+	}
+	vm := newTestValidatorMonitor(t, rpc, config.Validators{IDs: []string{"1", "0x" + strings.Repeat("ab", 48)}})
+
+	_, err := vm.checkValidators(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "only 1 of 2 configured validators found") {
+		t.Fatalf("expected missing validator error, got %v", err)
+	}
+}
+
+func TestValidators_InvalidID(t *testing.T) {
+	conf := &config.Config{Log: logrus.New()}
+	_, err := NewValidatorMonitor(conf, &fakeValidatorRPC{}, config.Endpoint{Validators: config.Validators{IDs: []string{"0x1234"}}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid validator public key") {
+		t.Fatalf("expected invalid public key error, got %v", err)
+	}
+}
@@ -2,97 +2,301 @@ package execution
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/cockroachdb/errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/sirupsen/logrus"
 
 	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/backoff"
 	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
 	"github.com/numbergroup/eth-monitor/pkg/monitor"
 )
 
+const (
+	minResubscribeDelay = time.Second
+	maxResubscribeDelay = time.Minute
+
+	// methodNotFoundCode is the JSON-RPC error code of nodes that do not support eth_subscribe
+	methodNotFoundCode = -32601
+)
+
 type RPCBlockNumber interface {
 	BlockNumber(ctx context.Context) (uint64, error)
 }
 
+// RPCNewHeads subscribes to new chain heads, which is only supported over WebSocket connections.
+type RPCNewHeads interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
 type BlockNumberMonitor struct {
-	alertChannels    []alert.Alert
-	conf             *config.Config
-	client           RPCBlockNumber
-	endpoint         config.Endpoint
+	alerts   *alert.Lifecycle
+	conf     *config.Config
+	client   RPCBlockNumber
+	endpoint config.Endpoint
+	log      logrus.Ext1FieldLogger
+	tracker  *monitor.Tracker
+
+	mu               sync.Mutex
 	lastBlockNumber  uint64
 	lastNewBlockTime time.Time
-	log              logrus.Ext1FieldLogger
+	lastHash         common.Hash // Only known from subscribed heads
+	lastParentHash   common.Hash
+	subscribed       bool // Heads are pushed through the newHeads subscription, instead of polled
 }
 
 func NewBlockNumberMonitor(conf *config.Config, alertChannels []alert.Alert, rpcClient RPCBlockNumber, endpoint config.Endpoint) (monitor.Monitor, error) {
 	out := &BlockNumberMonitor{
-		alertChannels: alertChannels,
-		conf:          conf,
-		client:        rpcClient,
-		endpoint:      endpoint,
+		conf:     conf,
+		client:   rpcClient,
+		endpoint: endpoint,
 	}
 	out.log = conf.Log.WithFields(logrus.Fields{
 		"name":     out.Name(),
 		"endpoint": endpoint.Name,
 	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
 	return out, nil
 }
 
 func (m *BlockNumberMonitor) checkNewBlock(ctx context.Context) error {
+	start := time.Now()
 	blockNumber, err := m.client.BlockNumber(ctx)
+	metrics.ObserveRPC(m.endpoint.Name, "eth_blockNumber", start, err)
 	if err != nil {
 		return errors.Wrap(err, "failed to get block number")
 	}
 
-	if blockNumber == m.lastBlockNumber {
-		elapsedTime := time.Since(m.lastNewBlockTime)
-		if elapsedTime > m.endpoint.NewBlockMaxDuration {
-			return errors.Errorf("no new block for %s, expected less than %s", elapsedTime, m.endpoint.NewBlockMaxDuration.Seconds())
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case m.lastNewBlockTime.IsZero() || blockNumber > m.lastBlockNumber:
+		m.lastBlockNumber = blockNumber
+		m.lastNewBlockTime = time.Now()
+	case blockNumber < m.lastBlockNumber:
+		previous := m.lastBlockNumber
+		m.lastBlockNumber = blockNumber
+		m.lastNewBlockTime = time.Now()
+		return errors.Errorf("block number decreased from %d to %d", previous, blockNumber)
+	default:
+		// The age of the last new block keeps growing until the block number changes
+		if elapsed := time.Since(m.lastNewBlockTime); elapsed > m.endpoint.NewBlockMaxDuration {
+			return errors.Errorf("no new block for %s, expected less than %s", elapsed.Round(time.Second), m.endpoint.NewBlockMaxDuration)
 		}
 	}
+	return nil
+}
 
-	if blockNumber < m.lastBlockNumber {
-		m.lastBlockNumber = blockNumber
-		m.lastNewBlockTime = time.Now()
-		return errors.Errorf("block number decreased from %d to %d", m.lastBlockNumber, blockNumber)
+// checkHeadAge returns an error if no head was received through the subscription for longer than NewBlockMaxDuration.
+func (m *BlockNumberMonitor) checkHeadAge() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elapsed := time.Since(m.lastNewBlockTime); elapsed > m.endpoint.NewBlockMaxDuration {
+		return errors.Errorf("no new head for %s, expected less than %s", elapsed.Round(time.Second), m.endpoint.NewBlockMaxDuration)
 	}
-	m.lastBlockNumber = blockNumber
-	m.lastNewBlockTime = time.Now()
 	return nil
 }
 
+func (m *BlockNumberMonitor) onHead(head *types.Header) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	number := head.Number.Uint64()
+	if m.lastHash != (common.Hash{}) && head.ParentHash != m.lastHash {
+		m.log.WithFields(logrus.Fields{
+			"block":       number,
+			"hash":        head.Hash(),
+			"parent_hash": head.ParentHash,
+			"last_hash":   m.lastHash,
+		}).Debug("new head does not build on the previous head, chain reorganized")
+	}
+	m.lastBlockNumber = number
+	m.lastNewBlockTime = time.Now()
+	m.lastHash = head.Hash()
+	m.lastParentHash = head.ParentHash
+	metrics.BlockNumber.WithLabelValues(m.endpoint.Name).Set(float64(number))
+}
+
+func (m *BlockNumberMonitor) setSubscribed(subscribed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribed = subscribed
+	// The first head may take up to a block time to arrive, so count from the moment heads can be received
+	if subscribed && m.lastNewBlockTime.IsZero() {
+		m.lastNewBlockTime = time.Now()
+	}
+}
+
+// subscribeHeads records heads as they are pushed, until the subscription fails or ctx is cancelled.
+func (m *BlockNumberMonitor) subscribeHeads(ctx context.Context, client RPCNewHeads) error {
+	heads := make(chan *types.Header, 16)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to new heads")
+	}
+	defer sub.Unsubscribe()
+
+	m.setSubscribed(true)
+	defer m.setSubscribed(false)
+	m.log.Info("subscribed to new heads")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return errors.Wrap(err, "new heads subscription dropped")
+		case head := <-heads:
+			m.onHead(head)
+		}
+	}
+}
+
+// subscribe keeps the newHeads subscription alive, resubscribing with exponential backoff until ctx is cancelled.
+// The block number is polled while the subscription is down, and for good if the endpoint does not support it.
+func (m *BlockNumberMonitor) subscribe(ctx context.Context, client RPCNewHeads) {
+	retry := backoff.New(minResubscribeDelay, maxResubscribeDelay)
+	for {
+		start := time.Now()
+		err := m.subscribeHeads(ctx, client)
+		if ctx.Err() != nil {
+			return
+		}
+		if subscriptionUnsupported(err) {
+			m.log.WithError(err).Warn("new heads subscription not supported, falling back to polling")
+			return
+		}
+		// A subscription that was established for a while is not a failed attempt
+		if time.Since(start) > maxResubscribeDelay {
+			retry.Reset()
+		}
+
+		delay := retry.Next()
+		m.log.WithError(err).WithFields(logrus.Fields{
+			"attempt": retry.Attempt(),
+			"delay":   delay,
+		}).Warn("new heads subscription failed, polling until resubscribed")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func subscriptionUnsupported(err error) bool {
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		return true
+	}
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode
+}
+
+// check polls the block number, unless heads are pushed through the subscription.
+func (m *BlockNumberMonitor) check(ctx context.Context) error {
+	m.mu.Lock()
+	subscribed := m.subscribed
+	m.mu.Unlock()
+	if subscribed {
+		return m.checkHeadAge()
+	}
+	return m.checkNewBlock(ctx)
+}
+
+// nextCheck returns how long to wait before the next check. While subscribed, the check runs as soon as
+// NewBlockMaxDuration has passed since the last head, rather than at the next poll.
+func (m *BlockNumberMonitor) nextCheck() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subscribed && !m.lastNewBlockTime.IsZero() {
+		untilStall := time.Until(m.lastNewBlockTime.Add(m.endpoint.NewBlockMaxDuration)) + time.Millisecond
+		if untilStall > 0 && untilStall < m.endpoint.PollDuration {
+			return untilStall
+		}
+	}
+	return m.endpoint.PollDuration
+}
+
 func (m *BlockNumberMonitor) Name() string {
 	return "execution::BlockNumberMonitor::" + m.endpoint.Name
 }
 
+func (m *BlockNumberMonitor) Status() monitor.Status {
+	return m.tracker.Status()
+}
+
 func (m *BlockNumberMonitor) Run(ctx context.Context) {
+	// The subscription stops with ctx, Run returns once it has
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	if m.endpoint.Subscribe {
+		if client, ok := m.client.(RPCNewHeads); ok {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.subscribe(ctx, client)
+			}()
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			m.log.Info("monitoring stopped")
 			return
 		default:
-			if err := m.checkNewBlock(ctx); err != nil {
+			err := m.check(ctx)
+			m.mu.Lock()
+			lastBlockNumber, lastNewBlockTime := m.lastBlockNumber, m.lastNewBlockTime
+			status := map[string]any{
+				"block":          lastBlockNumber,
+				"last_new_block": lastNewBlockTime,
+				"subscribed":     m.subscribed,
+			}
+			if m.lastHash != (common.Hash{}) {
+				status["hash"] = m.lastHash.Hex()
+				status["parent_hash"] = m.lastParentHash.Hex()
+			}
+			m.mu.Unlock()
+
+			m.tracker.Record(status, err)
+			if !lastNewBlockTime.IsZero() {
+				metrics.LastNewBlockAge.WithLabelValues(m.endpoint.Name, config.TypeExecution).Set(time.Since(lastNewBlockTime).Seconds())
+			}
+			if err != nil {
 				m.log.WithError(err).Error("health check failed, raising alert")
-				alertErr := alert.RaiseAll(ctx, m.log, m.alertChannels, alert.Message{
+				alertErr := m.alerts.Trigger(ctx, alert.Message{
 					Message:  err.Error(),
-					Severity: alert.Error,
-					Name:     m.endpoint.Name,
+					Severity: m.endpoint.Severity.BlockStall.OrDefault(),
 				})
 				if alertErr != nil {
 					m.conf.Log.WithError(alertErr).Error("failed to raise alert")
 				}
 
 			} else {
+				metrics.BlockNumber.WithLabelValues(m.endpoint.Name).Set(float64(lastBlockNumber))
 				m.log.WithFields(logrus.Fields{
-					"block": m.lastBlockNumber}).Info("Endpoint is healthy")
+					"block": lastBlockNumber}).Info("Endpoint is healthy")
+				if alertErr := m.alerts.Resolve(ctx); alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to resolve alert")
+				}
 			}
 		}
 
 		select {
-		case <-time.After(m.endpoint.PollDuration):
+		case <-time.After(m.nextCheck()):
 			continue
 		case <-ctx.Done():
 			m.log.Info("monitoring stopped")
@@ -2,10 +2,16 @@ package execution
 
 import (
 	"context"
+	"errors"
+	"math/big"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
 	"github.com/numbergroup/eth-monitor/pkg/config"
 	"github.com/sirupsen/logrus"
 )
@@ -55,11 +61,32 @@ func TestCheckNewBlock_UnchangedWithinThreshold_OK(t *testing.T) {
 	ep := config.Endpoint{NewBlockMaxDuration: 2 * time.Second}
 	m := newTestMonitor(t, rpc, ep)
 	m.lastBlockNumber = 10
-	m.lastNewBlockTime = time.Now().Add(-500 * time.Millisecond)
+	lastNewBlockTime := time.Now().Add(-500 * time.Millisecond)
+	m.lastNewBlockTime = lastNewBlockTime
 
 	if err := m.checkNewBlock(t.Context()); err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
+	if !m.lastNewBlockTime.Equal(lastNewBlockTime) {
+		t.Fatalf("expected lastNewBlockTime to be kept while the block number is unchanged, got %v", m.lastNewBlockTime)
+	}
+}
+
+func TestCheckNewBlock_UnchangedAcrossPolls_Error(t *testing.T) {
+	rpc := &fakeRPC{ret: 10}
+	ep := config.Endpoint{NewBlockMaxDuration: 300 * time.Millisecond}
+	m := newTestMonitor(t, rpc, ep)
+
+	for i := 0; i < 2; i++ {
+		if err := m.checkNewBlock(t.Context()); err != nil {
+			t.Fatalf("poll %d: expected no error, got %v", i, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if err := m.checkNewBlock(t.Context()); err == nil || !strings.Contains(err.Error(), "no new block") {
+		t.Fatalf("expected a stall polled more often than new_block_max_duration, got %v", err)
+	}
 }
 
 func TestCheckNewBlock_UnchangedExceedsThreshold_Error(t *testing.T) {
@@ -89,7 +116,7 @@ func TestCheckNewBlock_Decrease_Error(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error on decreased block number, got nil")
 	}
-	if !strings.Contains(err.Error(), "block number decreased") {
+	if err.Error() != "block number decreased from 20 to 15" {
 		t.Fatalf("unexpected error message: %v", err)
 	}
 	if m.lastBlockNumber != 15 {
@@ -126,3 +153,137 @@ func TestName_Format(t *testing.T) {
 		t.Fatalf("unexpected name, got %q want %q", got, want)
 	}
 }
+
+// fakeSubscription implements ethereum.Subscription for fakeHeadsRPC.
+type fakeSubscription struct {
+	errc chan error
+}
+
+func (s *fakeSubscription) Unsubscribe()      {}
+func (s *fakeSubscription) Err() <-chan error { return s.errc }
+
+// fakeHeadsRPC pushes heads written to heads through a newHeads subscription.
+type fakeHeadsRPC struct {
+	fakeRPC
+	heads chan *types.Header
+	sub   *fakeSubscription
+	err   error
+}
+
+func (f *fakeHeadsRPC) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) { //nolint:revive // match interface
+	if f.err != nil {
+		return nil, f.err
+	}
+	go func() {
+		for head := range f.heads {
+			ch <- head
+		}
+	}()
+	return f.sub, nil
+}
+
+func TestSubscribeHeads_RecordsHeads(t *testing.T) {
+	rpc := &fakeHeadsRPC{heads: make(chan *types.Header), sub: &fakeSubscription{errc: make(chan error, 1)}}
+	ep := config.Endpoint{NewBlockMaxDuration: 5 * time.Second, PollDuration: time.Second}
+	m := newTestMonitor(t, rpc, ep)
+
+	done := make(chan error, 1)
+	go func() { done <- m.subscribeHeads(t.Context(), rpc) }()
+
+	parent := &types.Header{Number: big.NewInt(41)}
+	head := &types.Header{Number: big.NewInt(42), ParentHash: parent.Hash()}
+	rpc.heads <- parent
+	rpc.heads <- head
+	close(rpc.heads)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		m.mu.Lock()
+		number, hash := m.lastBlockNumber, m.lastHash
+		m.mu.Unlock()
+		if number == 42 {
+			if hash != head.Hash() {
+				t.Fatalf("unexpected head hash got %s want %s", hash, head.Hash())
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("head was not recorded, last block %d", number)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if m.lastParentHash != parent.Hash() {
+		t.Fatalf("unexpected parent hash got %s want %s", m.lastParentHash, parent.Hash())
+	}
+	if err := m.check(t.Context()); err != nil {
+		t.Fatalf("expected no error while heads arrive, got %v", err)
+	}
+
+	rpc.sub.errc <- errors.New("connection reset")
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "subscription dropped") {
+		t.Fatalf("expected dropped subscription error, got %v", err)
+	}
+	if m.subscribed {
+		t.Fatal("expected monitor to fall back to polling after the subscription dropped")
+	}
+}
+
+func TestCheck_SubscribedStall_Error(t *testing.T) {
+	rpc := &fakeRPC{ret: 10}
+	ep := config.Endpoint{NewBlockMaxDuration: time.Second}
+	m := newTestMonitor(t, rpc, ep)
+	m.subscribed = true
+	m.lastBlockNumber = 10
+	m.lastNewBlockTime = time.Now().Add(-10 * time.Second)
+
+	err := m.check(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "no new head") {
+		t.Fatalf("expected no new head error, got %v", err)
+	}
+}
+
+func TestSubscribe_UnsupportedFallsBackToPolling(t *testing.T) {
+	rpc := &fakeHeadsRPC{err: gethrpc.ErrNotificationsUnsupported}
+	ep := config.Endpoint{NewBlockMaxDuration: time.Second, PollDuration: time.Second}
+	m := newTestMonitor(t, rpc, ep)
+
+	done := make(chan struct{})
+	go func() {
+		m.subscribe(t.Context(), rpc)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected subscribe to give up when subscriptions are not supported")
+	}
+	if m.subscribed {
+		t.Fatal("expected monitor to poll")
+	}
+}
+
+// slowUnsubscribeRPC holds the newHeads subscription until ctx is done, and takes a while to give it up.
+type slowUnsubscribeRPC struct {
+	fakeRPC
+	released atomic.Bool
+}
+
+func (f *slowUnsubscribeRPC) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) { //nolint:revive // match interface
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	f.released.Store(true)
+	return nil, ctx.Err()
+}
+
+func TestRun_WaitsForSubscription(t *testing.T) {
+	rpc := &slowUnsubscribeRPC{fakeRPC: fakeRPC{ret: 10}}
+	ep := config.Endpoint{NewBlockMaxDuration: time.Minute, PollDuration: time.Minute, Subscribe: true}
+	m := newTestMonitor(t, rpc, ep)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	m.Run(ctx)
+	if !rpc.released.Load() {
+		t.Fatal("expected Run to return once the subscription stopped")
+	}
+}
@@ -0,0 +1,38 @@
+package execution
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+	"github.com/numbergroup/eth-monitor/pkg/monitor/generic"
+)
+
+type RPCChainID interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+type chainIdentity struct {
+	endpoint config.Endpoint
+	client   RPCChainID
+}
+
+func (ci *chainIdentity) ChainIdentity(ctx context.Context) (map[string]string, error) {
+	start := time.Now()
+	chainID, err := ci.client.ChainID(ctx)
+	metrics.ObserveRPC(ci.endpoint.Name, "eth_chainId", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"chain_id": chainID.String()}, nil
+}
+
+func NewChainMonitor(conf *config.Config, alertChannels []alert.Alert, rpcClient RPCChainID, endpoint config.Endpoint) monitor.Monitor {
+	expected := map[string]string{"chain_id": strconv.FormatUint(endpoint.ChainID, 10)}
+	return generic.NewChainMonitor(conf, alertChannels, &chainIdentity{endpoint: endpoint, client: rpcClient}, endpoint, config.TypeExecution, expected)
+}
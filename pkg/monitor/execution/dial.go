@@ -0,0 +1,21 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/numbergroup/eth-monitor/pkg/auth"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// Dial connects to the endpoint, sending its headers, basic auth and JWT with every HTTP request
+// and WebSocket handshake.
+func Dial(ctx context.Context, endpoint config.Endpoint) (*ethclient.Client, error) {
+	rpcClient, err := rpc.DialOptions(ctx, endpoint.URL, rpc.WithHTTPAuth(auth.New(endpoint).Apply))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcClient), nil
+}
@@ -0,0 +1,34 @@
+package execution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func TestDial_SendsHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	defer srv.Close()
+
+	client, err := Dial(t.Context(), config.Endpoint{URL: srv.URL, Headers: map[string]string{"X-Api-Key": "key"}})
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	block, err := client.BlockNumber(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if block != 16 {
+		t.Fatalf("expected block 16, got %d", block)
+	}
+	if got := (<-headers).Get("X-Api-Key"); got != "key" {
+		t.Fatalf("expected custom header, got %q", got)
+	}
+}
@@ -0,0 +1,25 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor/generic"
+)
+
+type headClient struct {
+	client RPCBlockNumber
+}
+
+// Head returns the endpoint's latest block number.
+func (hc *headClient) Head(ctx context.Context) (uint64, error) {
+	return hc.client.BlockNumber(ctx)
+}
+
+func NewHeadClient(ctx context.Context, endpoint config.Endpoint) (generic.RPCHead, error) {
+	rpcClient, err := Dial(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &headClient{client: rpcClient}, nil
+}
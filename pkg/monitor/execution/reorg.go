@@ -0,0 +1,273 @@
+package execution
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+// reorgHistory is the number of recent blocks kept to find the common ancestor of a reorg.
+// Deeper reorgs are still detected, but their depth is only known to be at least this.
+const reorgHistory = 64
+
+type RPCHeaders interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+type blockRef struct {
+	Number     uint64
+	Hash       common.Hash
+	ParentHash common.Hash
+}
+
+func newBlockRef(header *types.Header) blockRef {
+	return blockRef{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+	}
+}
+
+// reorg describes a chain reorganization, Depth is the number of blocks of the old chain that were replaced.
+type reorg struct {
+	Depth     uint64
+	AtLeast   bool // The common ancestor is older than the kept history
+	OldHead   blockRef
+	NewHead   blockRef
+	Ancestor  blockRef
+	Timestamp time.Time
+}
+
+// ReorgMonitor polls the head of an execution endpoint and compares it against the recently seen
+// canonical blocks, to detect reorgs and alert when one is deeper than the configured maximum.
+type ReorgMonitor struct {
+	alerts    *alert.Lifecycle
+	conf      *config.Config
+	client    RPCHeaders
+	endpoint  config.Endpoint
+	chain     []blockRef // Recently seen canonical blocks, oldest first
+	lastReorg *reorg
+	log       logrus.Ext1FieldLogger
+	tracker   *monitor.Tracker
+}
+
+func NewReorgMonitor(conf *config.Config, alertChannels []alert.Alert, rpcClient RPCHeaders, endpoint config.Endpoint) (monitor.Monitor, error) {
+	out := &ReorgMonitor{
+		conf:     conf,
+		client:   rpcClient,
+		endpoint: endpoint,
+	}
+	out.log = conf.Log.WithFields(logrus.Fields{
+		"name":     out.Name(),
+		"endpoint": endpoint.Name,
+	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
+	return out, nil
+}
+
+func (m *ReorgMonitor) header(ctx context.Context, number *big.Int) (blockRef, error) {
+	start := time.Now()
+	header, err := m.client.HeaderByNumber(ctx, number)
+	metrics.ObserveRPC(m.endpoint.Name, "eth_getBlockByNumber", start, err)
+	if err != nil {
+		return blockRef{}, errors.Wrapf(err, "failed to get block %v", number)
+	}
+	if header == nil || header.Number == nil {
+		return blockRef{}, errors.Errorf("empty header for block %v", number)
+	}
+	return newBlockRef(header), nil
+}
+
+func (m *ReorgMonitor) push(block blockRef) {
+	m.chain = append(m.chain, block)
+	if len(m.chain) > reorgHistory {
+		m.chain = m.chain[len(m.chain)-reorgHistory:]
+	}
+}
+
+// observe records head as the new canonical head, returning the reorg it caused if it does not build on the kept chain.
+func (m *ReorgMonitor) observe(ctx context.Context, head blockRef) (*reorg, error) {
+	if len(m.chain) == 0 {
+		m.push(head)
+		return nil, nil
+	}
+	tip := m.chain[len(m.chain)-1]
+	if head.Hash == tip.Hash {
+		return nil, nil
+	}
+	if head.Number == tip.Number+1 && head.ParentHash == tip.Hash {
+		m.push(head)
+		return nil, nil
+	}
+	for _, block := range m.chain {
+		if block.Hash == head.Hash {
+			// A lagging or load-balanced node reports an older head of the same chain, nothing was replaced
+			m.log.WithFields(logrus.Fields{
+				"block": head.Number,
+				"tip":   tip.Number,
+			}).Debug("head went back to an already seen block, ignoring")
+			return nil, nil
+		}
+	}
+
+	// Walk back from the tip to the most recent kept block that is still canonical
+	for i := len(m.chain) - 1; i >= 0; i-- {
+		block := m.chain[i]
+		if block.Number > head.Number {
+			continue
+		}
+		canonical := head
+		if block.Number < head.Number {
+			var err error
+			canonical, err = m.header(ctx, new(big.Int).SetUint64(block.Number))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if canonical.Hash != block.Hash {
+			continue
+		}
+
+		m.chain = m.chain[:i+1]
+		m.push(head)
+		if block == tip {
+			// The head moved ahead by more than one block without replacing any
+			return nil, nil
+		}
+		return &reorg{
+			Depth:     tip.Number - block.Number,
+			OldHead:   tip,
+			NewHead:   head,
+			Ancestor:  block,
+			Timestamp: time.Now(),
+		}, nil
+	}
+
+	oldest := m.chain[0]
+	m.chain = m.chain[:0]
+	m.push(head)
+	return &reorg{
+		Depth:     tip.Number - oldest.Number + 1,
+		AtLeast:   true,
+		OldHead:   tip,
+		NewHead:   head,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// checkReorg returns the reorg caused by the current head, if any, and an error if it is deeper than the maximum.
+func (m *ReorgMonitor) checkReorg(ctx context.Context) (*reorg, error) {
+	head, err := m.header(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	event, err := m.observe(ctx, head)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	m.lastReorg = event
+	metrics.Reorgs.WithLabelValues(m.endpoint.Name, config.TypeExecution).Inc()
+	metrics.ReorgDepth.WithLabelValues(m.endpoint.Name, config.TypeExecution).Set(float64(event.Depth))
+	m.log.WithFields(logrus.Fields{
+		"depth":    event.Depth,
+		"old_head": event.OldHead.Hash,
+		"new_head": event.NewHead.Hash,
+	}).Warn("chain reorganization detected")
+
+	if event.Depth <= m.endpoint.Reorg.MaxDepth {
+		return event, nil
+	}
+	depth := "depth %d"
+	if event.AtLeast {
+		depth = "depth of at least %d"
+	}
+	return event, errors.Errorf("reorg of "+depth+" replaced head %d (%s) with %d (%s), expected at most %d",
+		event.Depth, event.OldHead.Number, event.OldHead.Hash, event.NewHead.Number, event.NewHead.Hash, m.endpoint.Reorg.MaxDepth)
+}
+
+func (m *ReorgMonitor) Name() string {
+	return "execution::ReorgMonitor::" + m.endpoint.Name
+}
+
+func (m *ReorgMonitor) Status() monitor.Status {
+	return m.tracker.Status()
+}
+
+func (m *ReorgMonitor) status() map[string]any {
+	status := map[string]any{}
+	if len(m.chain) > 0 {
+		head := m.chain[len(m.chain)-1]
+		status["block"] = head.Number
+		status["hash"] = head.Hash.Hex()
+	}
+	if m.lastReorg != nil {
+		lastReorg := map[string]any{
+			"depth":    m.lastReorg.Depth,
+			"old_head": m.lastReorg.OldHead.Hash.Hex(),
+			"new_head": m.lastReorg.NewHead.Hash.Hex(),
+			"time":     m.lastReorg.Timestamp,
+		}
+		if !m.lastReorg.AtLeast {
+			lastReorg["ancestor"] = m.lastReorg.Ancestor.Number
+		}
+		status["last_reorg"] = lastReorg
+	}
+	return status
+}
+
+// Run alerts when a check observes a reorg deeper than the maximum, and resolves at the next check without one.
+func (m *ReorgMonitor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		default:
+			event, err := m.checkReorg(ctx)
+			m.tracker.Record(m.status(), err)
+			if err != nil {
+				m.log.WithError(err).Error("health check failed, raising alert")
+				msg := alert.Message{
+					Message:  err.Error(),
+					Severity: m.endpoint.Severity.Reorg.OrDefault(),
+				}
+				if event != nil {
+					msg.Metadata = map[string]any{
+						"depth":    event.Depth,
+						"old_head": event.OldHead.Hash.Hex(),
+						"new_head": event.NewHead.Hash.Hex(),
+					}
+				}
+				if alertErr := m.alerts.Trigger(ctx, msg); alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to raise alert")
+				}
+			} else {
+				m.log.WithFields(logrus.Fields{
+					"block": m.chain[len(m.chain)-1].Number}).Info("Endpoint is healthy")
+				if alertErr := m.alerts.Resolve(ctx); alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to resolve alert")
+				}
+			}
+		}
+
+		select {
+		case <-time.After(m.endpoint.PollDuration):
+			continue
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		}
+	}
+}
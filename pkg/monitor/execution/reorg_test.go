@@ -0,0 +1,166 @@
+package execution
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// fakeChain serves headers of a canonical chain, which tests replace to simulate reorgs.
+type fakeChain struct {
+	headers []*types.Header // Indexed by block number
+}
+
+// extend appends n blocks to the chain, with fork mixed into their extra data to create distinct hashes.
+func (f *fakeChain) extend(n int, fork byte) {
+	for i := 0; i < n; i++ {
+		header := &types.Header{Number: big.NewInt(int64(len(f.headers))), Extra: []byte{fork}}
+		if len(f.headers) > 0 {
+			header.ParentHash = f.headers[len(f.headers)-1].Hash()
+		}
+		f.headers = append(f.headers, header)
+	}
+}
+
+// fork replaces the blocks above number with n blocks of a competing chain.
+func (f *fakeChain) fork(number uint64, n int, fork byte) {
+	f.headers = f.headers[:number+1]
+	f.extend(n, fork)
+}
+
+func (f *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) { //nolint:revive // match interface
+	if number == nil {
+		return f.headers[len(f.headers)-1], nil
+	}
+	return f.headers[number.Uint64()], nil
+}
+
+func newTestReorgMonitor(t *testing.T, chain *fakeChain, maxDepth uint64) *ReorgMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	mon, err := NewReorgMonitor(conf, nil, chain, config.Endpoint{Reorg: config.Reorg{MaxDepth: maxDepth}})
+	if err != nil {
+		t.Fatalf("failed to create monitor: %v", err)
+	}
+	return mon.(*ReorgMonitor)
+}
+
+// observeAll feeds every block of the chain to the monitor, as if it had seen each head.
+func observeAll(t *testing.T, m *ReorgMonitor, chain *fakeChain) {
+	t.Helper()
+	for _, header := range chain.headers {
+		if _, err := m.observe(t.Context(), newBlockRef(header)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestReorgMonitor_NoReorg(t *testing.T) {
+	chain := &fakeChain{}
+	chain.extend(10, 0)
+	m := newTestReorgMonitor(t, chain, 2)
+
+	for range 3 {
+		if event, err := m.checkReorg(t.Context()); event != nil || err != nil {
+			t.Fatalf("expected no reorg, got %+v %v", event, err)
+		}
+		// The head may advance by more than one block between checks
+		chain.extend(3, 0)
+	}
+}
+
+func TestReorgMonitor_ShallowReorg(t *testing.T) {
+	chain := &fakeChain{}
+	chain.extend(10, 0)
+	m := newTestReorgMonitor(t, chain, 2)
+	observeAll(t, m, chain)
+
+	oldHead := chain.headers[9].Hash()
+	chain.fork(8, 2, 1)
+	event, err := m.checkReorg(t.Context())
+	if err != nil {
+		t.Fatalf("expected reorg within max depth to not error, got %v", err)
+	}
+	if event == nil || event.Depth != 1 || event.Ancestor.Number != 8 {
+		t.Fatalf("expected reorg of depth 1 from block 8, got %+v", event)
+	}
+	if event.OldHead.Hash != oldHead || event.NewHead.Hash != chain.headers[10].Hash() {
+		t.Fatalf("unexpected heads %+v", event)
+	}
+}
+
+func TestReorgMonitor_RewindIsNotReorg(t *testing.T) {
+	chain := &fakeChain{}
+	chain.extend(10, 0)
+	m := newTestReorgMonitor(t, chain, 1)
+	observeAll(t, m, chain)
+
+	// A lagging node reports a head already seen
+	event, err := m.observe(t.Context(), newBlockRef(chain.headers[6]))
+	if event != nil || err != nil {
+		t.Fatalf("expected no reorg on a rewind, got %+v %v", event, err)
+	}
+	if len(m.chain) != 10 || m.chain[len(m.chain)-1].Number != 9 {
+		t.Fatalf("expected the kept chain to be unchanged, got %d blocks", len(m.chain))
+	}
+
+	chain.extend(1, 0)
+	if event, err := m.checkReorg(t.Context()); event != nil || err != nil {
+		t.Fatalf("expected no reorg once the head advances, got %+v %v", event, err)
+	}
+	for i := 1; i < len(m.chain); i++ {
+		if m.chain[i].Number != m.chain[i-1].Number+1 {
+			t.Fatalf("expected no duplicate blocks, got %d after %d", m.chain[i].Number, m.chain[i-1].Number)
+		}
+	}
+}
+
+func TestReorgMonitor_DeepReorg_Error(t *testing.T) {
+	chain := &fakeChain{}
+	chain.extend(5, 0)
+	m := newTestReorgMonitor(t, chain, 2)
+	for range 5 {
+		if _, err := m.checkReorg(t.Context()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		chain.extend(1, 0)
+	}
+
+	// The last checked head is block 8
+	chain.fork(5, 4, 1)
+	event, err := m.checkReorg(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "reorg of depth 3") {
+		t.Fatalf("expected reorg of depth 3 error, got %v", err)
+	}
+	if event.Ancestor.Number != 5 {
+		t.Fatalf("expected common ancestor 5, got %d", event.Ancestor.Number)
+	}
+
+	// The new chain is kept, so extending it is not a reorg
+	chain.extend(1, 1)
+	if event, err := m.checkReorg(t.Context()); event != nil || err != nil {
+		t.Fatalf("expected no reorg after recovery, got %+v %v", event, err)
+	}
+}
+
+func TestReorgMonitor_BeyondHistory(t *testing.T) {
+	chain := &fakeChain{}
+	chain.extend(reorgHistory+10, 0)
+	m := newTestReorgMonitor(t, chain, 2)
+	observeAll(t, m, chain)
+
+	chain.fork(0, reorgHistory+10, 1)
+	_, err := m.checkReorg(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "depth of at least 64") {
+		t.Fatalf("expected reorg beyond history error, got %v", err)
+	}
+	if len(m.chain) != 1 || m.chain[0].Hash != chain.headers[len(chain.headers)-1].Hash() {
+		t.Fatalf("expected history to restart from the new head, got %+v", m.chain)
+	}
+}
@@ -4,15 +4,13 @@ import (
 	"context"
 	"sync"
 
-	"github.com/ethereum/go-ethereum/ethclient"
-
 	"github.com/numbergroup/eth-monitor/pkg/alert"
 	"github.com/numbergroup/eth-monitor/pkg/config"
 	"github.com/numbergroup/eth-monitor/pkg/monitor"
 )
 
-func RunMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []alert.Alert) error {
-	rpcClient, err := ethclient.DialContext(ctx, endpoint.URL)
+func RunMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Config, endpoint config.Endpoint, alertChannels []alert.Alert, registry *monitor.Registry) error {
+	rpcClient, err := Dial(ctx, endpoint)
 	if err != nil {
 		conf.Log.WithError(err).WithField("endpoint", endpoint.Name).Error("failed to connect to RPC client")
 		return err
@@ -27,11 +25,13 @@ func RunMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Co
 		conf.Log.WithField("name", m.Name()).Info("block number monitoring started")
 
 		defer waitGroup.Done()
+		registry.Register(m)
+		defer registry.Unregister(m)
 		m.Run(ctx)
 	}(mon)
 
 	// Start PeerCount monitor if configured
-	if endpoint.MinPeers > 0 {
+	if endpoint.PeerCountEnabled() {
 		peerMon, err := NewPeerCountMonitor(conf, alertChannels, rpcClient, endpoint)
 		if err != nil {
 			conf.Log.WithError(err).WithField("endpoint", endpoint.Name).Error("failed to create peer monitor")
@@ -42,8 +42,57 @@ func RunMonitors(ctx context.Context, waitGroup *sync.WaitGroup, conf *config.Co
 			conf.Log.WithField("name", m.Name()).Info("peer count monitoring started")
 
 			defer waitGroup.Done()
+			registry.Register(m)
+			defer registry.Unregister(m)
 			m.Run(ctx)
 		}(peerMon)
 	}
+
+	// Start SyncStatus monitor if configured
+	if endpoint.MaxSyncingDuration > 0 || endpoint.MaxSyncDistance > 0 {
+		syncMon, err := NewSyncStatusMonitor(conf, alertChannels, rpcClient, endpoint)
+		if err != nil {
+			conf.Log.WithError(err).WithField("endpoint", endpoint.Name).Error("failed to create sync status monitor")
+			return err
+		}
+		waitGroup.Add(1)
+		go func(m monitor.Monitor) {
+			conf.Log.WithField("name", m.Name()).Info("sync status monitoring started")
+
+			defer waitGroup.Done()
+			registry.Register(m)
+			defer registry.Unregister(m)
+			m.Run(ctx)
+		}(syncMon)
+	}
+
+	if endpoint.ChainCheckEnabled() {
+		waitGroup.Add(1)
+		go func(m monitor.Monitor) {
+			conf.Log.WithField("name", m.Name()).Info("chain monitoring started")
+
+			defer waitGroup.Done()
+			registry.Register(m)
+			defer registry.Unregister(m)
+			m.Run(ctx)
+		}(NewChainMonitor(conf, alertChannels, rpcClient, endpoint))
+	}
+
+	if endpoint.Reorg.Enabled() {
+		reorgMon, err := NewReorgMonitor(conf, alertChannels, rpcClient, endpoint)
+		if err != nil {
+			conf.Log.WithError(err).WithField("endpoint", endpoint.Name).Error("failed to create reorg monitor")
+			return err
+		}
+		waitGroup.Add(1)
+		go func(m monitor.Monitor) {
+			conf.Log.WithField("name", m.Name()).Info("reorg monitoring started")
+
+			defer waitGroup.Done()
+			registry.Register(m)
+			defer registry.Unregister(m)
+			m.Run(ctx)
+		}(reorgMon)
+	}
 	return nil
 }
@@ -0,0 +1,153 @@
+package execution
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+type RPCSyncProgress interface {
+	SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error)
+}
+
+// SyncStatusMonitor alerts when a node stays in syncing mode for too long, or falls too far
+// behind the chain head while syncing, which the block number monitor alone cannot detect.
+type SyncStatusMonitor struct {
+	alerts       *alert.Lifecycle
+	conf         *config.Config
+	client       RPCSyncProgress
+	endpoint     config.Endpoint
+	syncing      bool
+	syncingSince time.Time
+	currentBlock uint64
+	highestBlock uint64
+	log          logrus.Ext1FieldLogger
+	tracker      *monitor.Tracker
+}
+
+func NewSyncStatusMonitor(conf *config.Config, alertChannels []alert.Alert, rpcClient RPCSyncProgress, endpoint config.Endpoint) (monitor.Monitor, error) {
+	out := &SyncStatusMonitor{
+		conf:     conf,
+		client:   rpcClient,
+		endpoint: endpoint,
+	}
+	out.log = conf.Log.WithFields(logrus.Fields{
+		"name":     out.Name(),
+		"endpoint": endpoint.Name,
+	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
+	return out, nil
+}
+
+// distance returns how many blocks the node is behind the highest block it knows of.
+func (m *SyncStatusMonitor) distance() uint64 {
+	if m.highestBlock <= m.currentBlock {
+		return 0
+	}
+	return m.highestBlock - m.currentBlock
+}
+
+func (m *SyncStatusMonitor) checkSyncStatus(ctx context.Context) error {
+	start := time.Now()
+	progress, err := m.client.SyncProgress(ctx)
+	metrics.ObserveRPC(m.endpoint.Name, "eth_syncing", start, err)
+	if err != nil {
+		return errors.Wrap(err, "failed to get sync status")
+	}
+
+	if progress == nil {
+		m.syncing = false
+		m.syncingSince = time.Time{}
+		m.currentBlock = 0
+		m.highestBlock = 0
+		return nil
+	}
+
+	if !m.syncing {
+		m.syncing = true
+		m.syncingSince = time.Now()
+	}
+	m.currentBlock = progress.CurrentBlock
+	m.highestBlock = progress.HighestBlock
+
+	if m.endpoint.MaxSyncDistance > 0 && m.distance() > m.endpoint.MaxSyncDistance {
+		return errors.Errorf("node is syncing %d blocks behind (current %d, highest %d), expected at most %d",
+			m.distance(), m.currentBlock, m.highestBlock, m.endpoint.MaxSyncDistance)
+	}
+
+	elapsedTime := time.Since(m.syncingSince)
+	if m.endpoint.MaxSyncingDuration > 0 && elapsedTime > m.endpoint.MaxSyncingDuration {
+		return errors.Errorf("node has been syncing for %s (current %d, highest %d), expected less than %s",
+			elapsedTime.Round(time.Second), m.currentBlock, m.highestBlock, m.endpoint.MaxSyncingDuration)
+	}
+	return nil
+}
+
+func (m *SyncStatusMonitor) Name() string {
+	return "execution::SyncStatusMonitor::" + m.endpoint.Name
+}
+
+func (m *SyncStatusMonitor) Status() monitor.Status {
+	return m.tracker.Status()
+}
+
+func (m *SyncStatusMonitor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		default:
+			err := m.checkSyncStatus(ctx)
+			m.tracker.Record(map[string]any{
+				"syncing":       m.syncing,
+				"current_block": m.currentBlock,
+				"highest_block": m.highestBlock,
+				"distance":      m.distance(),
+			}, err)
+			metrics.SyncCurrentBlock.WithLabelValues(m.endpoint.Name).Set(float64(m.currentBlock))
+			metrics.SyncHighestBlock.WithLabelValues(m.endpoint.Name).Set(float64(m.highestBlock))
+			metrics.SyncDistance.WithLabelValues(m.endpoint.Name).Set(float64(m.distance()))
+			if err != nil {
+				m.log.WithError(err).Error("health check failed, raising alert")
+				alertErr := m.alerts.Trigger(ctx, alert.Message{
+					Message:  err.Error(),
+					Severity: m.endpoint.Severity.SyncStatus.OrDefault(),
+					Metadata: map[string]any{
+						"current_block": m.currentBlock,
+						"highest_block": m.highestBlock,
+						"distance":      m.distance(),
+					},
+				})
+				if alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to raise alert")
+				}
+			} else {
+				m.log.WithFields(logrus.Fields{
+					"syncing":  m.syncing,
+					"distance": m.distance(),
+				}).Info("Endpoint is healthy")
+				if alertErr := m.alerts.Resolve(ctx); alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to resolve alert")
+				}
+			}
+		}
+
+		select {
+		case <-time.After(m.endpoint.PollDuration):
+			continue
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package execution
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+// fakeSyncRPC implements the SyncProgress method used by the monitor.
+type fakeSyncRPC struct {
+	ret *ethereum.SyncProgress
+	err error
+}
+
+func (f *fakeSyncRPC) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) { //nolint:revive // match interface
+	return f.ret, f.err
+}
+
+func newTestSyncMonitor(t *testing.T, rpc RPCSyncProgress, ep config.Endpoint) *SyncStatusMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	mon, err := NewSyncStatusMonitor(conf, nil, rpc, ep)
+	if err != nil {
+		t.Fatalf("failed to create monitor: %v", err)
+	}
+	sm, ok := mon.(*SyncStatusMonitor)
+	if !ok {
+		t.Fatalf("unexpected monitor type: %T", mon)
+	}
+	return sm
+}
+
+func TestSyncStatus_NotSyncing_OK(t *testing.T) {
+	rpc := &fakeSyncRPC{}
+	ep := config.Endpoint{MaxSyncingDuration: time.Minute, MaxSyncDistance: 10}
+	m := newTestSyncMonitor(t, rpc, ep)
+
+	if err := m.checkSyncStatus(t.Context()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if m.syncing {
+		t.Fatal("expected syncing=false")
+	}
+}
+
+func TestSyncStatus_SmallGap_OK(t *testing.T) {
+	rpc := &fakeSyncRPC{ret: &ethereum.SyncProgress{CurrentBlock: 95, HighestBlock: 100}}
+	ep := config.Endpoint{MaxSyncingDuration: time.Minute, MaxSyncDistance: 10}
+	m := newTestSyncMonitor(t, rpc, ep)
+
+	if err := m.checkSyncStatus(t.Context()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !m.syncing {
+		t.Fatal("expected syncing=true")
+	}
+	if m.distance() != 5 {
+		t.Fatalf("expected distance=5, got %d", m.distance())
+	}
+}
+
+func TestSyncStatus_GapTooLarge_Error(t *testing.T) {
+	rpc := &fakeSyncRPC{ret: &ethereum.SyncProgress{CurrentBlock: 50, HighestBlock: 100}}
+	ep := config.Endpoint{MaxSyncDistance: 10}
+	m := newTestSyncMonitor(t, rpc, ep)
+
+	err := m.checkSyncStatus(t.Context())
+	if err == nil {
+		t.Fatal("expected error due to large sync gap, got nil")
+	}
+	if !strings.Contains(err.Error(), "50 blocks behind") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+}
+
+func TestSyncStatus_SyncingTooLong_Error(t *testing.T) {
+	rpc := &fakeSyncRPC{ret: &ethereum.SyncProgress{CurrentBlock: 99, HighestBlock: 100}}
+	ep := config.Endpoint{MaxSyncingDuration: time.Second}
+	m := newTestSyncMonitor(t, rpc, ep)
+	m.syncing = true
+	m.syncingSince = time.Now().Add(-time.Minute)
+
+	err := m.checkSyncStatus(t.Context())
+	if err == nil {
+		t.Fatal("expected error due to syncing too long, got nil")
+	}
+	if !strings.Contains(err.Error(), "has been syncing for") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+}
+
+func TestSyncStatus_FinishedSyncing_Resets(t *testing.T) {
+	rpc := &fakeSyncRPC{ret: &ethereum.SyncProgress{CurrentBlock: 99, HighestBlock: 100}}
+	ep := config.Endpoint{MaxSyncingDuration: time.Minute}
+	m := newTestSyncMonitor(t, rpc, ep)
+
+	if err := m.checkSyncStatus(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rpc.ret = nil
+	if err := m.checkSyncStatus(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.syncing || !m.syncingSince.IsZero() || m.distance() != 0 {
+		t.Fatalf("expected sync state to reset, got syncing=%v since=%v distance=%d", m.syncing, m.syncingSince, m.distance())
+	}
+}
+
+func TestSyncStatus_RPCError_Propagates(t *testing.T) {
+	rpc := &fakeSyncRPC{err: assertErr{}}
+	m := newTestSyncMonitor(t, rpc, config.Endpoint{MaxSyncDistance: 1})
+
+	err := m.checkSyncStatus(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "failed to get sync status") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
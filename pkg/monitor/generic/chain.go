@@ -0,0 +1,122 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+// RPCChainIdentity returns the values identifying the chain an endpoint follows, such as its chain ID or genesis, keyed by name.
+type RPCChainIdentity interface {
+	ChainIdentity(ctx context.Context) (map[string]string, error)
+}
+
+// ChainMonitor alerts when an endpoint follows a different chain than its configuration declares, for example
+// a testnet node configured as mainnet, which every other check would consider healthy.
+type ChainMonitor struct {
+	alerts   *alert.Lifecycle
+	conf     *config.Config
+	client   RPCChainIdentity
+	endpoint config.Endpoint
+	expected map[string]string
+	actual   map[string]string
+	log      logrus.Ext1FieldLogger
+	typeName string
+	tracker  *monitor.Tracker
+}
+
+func NewChainMonitor(conf *config.Config, alertChannels []alert.Alert, rpcClient RPCChainIdentity, endpoint config.Endpoint, typeName string, expected map[string]string) monitor.Monitor {
+	out := &ChainMonitor{
+		conf:     conf,
+		client:   rpcClient,
+		endpoint: endpoint,
+		expected: expected,
+		typeName: typeName,
+	}
+	out.log = conf.Log.WithFields(logrus.Fields{
+		"name":     out.Name(),
+		"endpoint": endpoint.Name,
+	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
+	return out
+}
+
+// checkChain returns a mismatch error if the endpoint does not follow the expected chain.
+func (m *ChainMonitor) checkChain(actual map[string]string) error {
+	var mismatches []string
+	for _, key := range slices.Sorted(maps.Keys(m.expected)) {
+		if want, got := m.expected[key], actual[key]; !strings.EqualFold(want, got) {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %s, expected %s", key, got, want))
+		}
+	}
+	if len(mismatches) > 0 {
+		return errors.Errorf("endpoint follows a different chain than configured: %s", strings.Join(mismatches, ", "))
+	}
+	return nil
+}
+
+func (m *ChainMonitor) Name() string {
+	return m.typeName + "::ChainMonitor::" + m.endpoint.Name
+}
+
+func (m *ChainMonitor) Status() monitor.Status {
+	return m.tracker.Status()
+}
+
+// Run checks the chain as soon as it starts, and then every poll. Failing to query the endpoint is
+// reported in the status but not alerted on, the other monitors of the endpoint alert when it is unreachable.
+func (m *ChainMonitor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		default:
+			actual, err := m.client.ChainIdentity(ctx)
+			if err != nil {
+				err = errors.Wrap(err, "failed to get chain identity")
+				m.log.WithError(err).Warn("chain check failed")
+				m.tracker.Record(map[string]any{"chain": m.actual}, err)
+				break
+			}
+			m.actual = actual
+
+			err = m.checkChain(actual)
+			m.tracker.Record(map[string]any{"chain": actual}, err)
+			if err != nil {
+				m.log.WithError(err).Error("health check failed, raising alert")
+				alertErr := m.alerts.Trigger(ctx, alert.Message{
+					Message:  err.Error(),
+					Severity: m.endpoint.Severity.Chain.Or(alert.Critical),
+				})
+				if alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to raise alert")
+				}
+			} else {
+				m.log.WithField("chain", actual).Info("Endpoint is healthy")
+				if alertErr := m.alerts.Resolve(ctx); alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to resolve alert")
+				}
+			}
+		}
+
+		select {
+		case <-time.After(m.endpoint.PollDuration):
+			continue
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package generic
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+type fakeChainRPC struct {
+	ret map[string]string
+	err error
+}
+
+func (f *fakeChainRPC) ChainIdentity(ctx context.Context) (map[string]string, error) {
+	return f.ret, f.err
+}
+
+// recordingChannel collects the messages raised on it.
+type recordingChannel struct {
+	msgs chan alert.Message
+}
+
+func (r *recordingChannel) Raise(ctx context.Context, msg alert.Message) error {
+	r.msgs <- msg
+	return nil
+}
+
+func (r *recordingChannel) Name() string                { return "recording" }
+func (r *recordingChannel) MinSeverity() alert.Severity { return "" }
+
+func newChainTestMonitor(t *testing.T, rpc RPCChainIdentity, channels []alert.Alert, expected map[string]string) *ChainMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New()}
+	mon, ok := NewChainMonitor(conf, channels, rpc, config.Endpoint{Name: "el", PollDuration: time.Hour}, config.TypeExecution, expected).(*ChainMonitor)
+	if !ok {
+		t.Fatal("unexpected monitor type")
+	}
+	return mon
+}
+
+func TestChainMonitor_Match(t *testing.T) {
+	m := newChainTestMonitor(t, &fakeChainRPC{}, nil, map[string]string{"genesis_validators_root": "0xABCD"})
+
+	if err := m.checkChain(map[string]string{"genesis_validators_root": "0xabcd", "genesis_fork_version": "0x00000000"}); err != nil {
+		t.Fatalf("expected hex values to match case-insensitively, got %v", err)
+	}
+}
+
+func TestChainMonitor_Mismatch(t *testing.T) {
+	m := newChainTestMonitor(t, &fakeChainRPC{}, nil, map[string]string{"chain_id": "1"})
+
+	err := m.checkChain(map[string]string{"chain_id": "17000"})
+	if err == nil || !strings.Contains(err.Error(), "chain_id is 17000, expected 1") {
+		t.Fatalf("expected chain id mismatch, got %v", err)
+	}
+}
+
+func TestChainMonitor_RunAlertsCritical(t *testing.T) {
+	channel := &recordingChannel{msgs: make(chan alert.Message, 1)}
+	m := newChainTestMonitor(t, &fakeChainRPC{ret: map[string]string{"chain_id": "17000"}}, []alert.Alert{channel}, map[string]string{"chain_id": "1"})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go m.Run(ctx)
+
+	select {
+	case msg := <-channel.msgs:
+		if msg.Severity != alert.Critical {
+			t.Fatalf("expected critical alert, got %s", msg.Severity)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an alert on startup")
+	}
+}
+
+func TestChainMonitor_RPCErrorNotAlerted(t *testing.T) {
+	channel := &recordingChannel{msgs: make(chan alert.Message, 1)}
+	m := newChainTestMonitor(t, &fakeChainRPC{err: assertErr{}}, []alert.Alert{channel}, map[string]string{"chain_id": "1"})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go m.Run(ctx)
+
+	select {
+	case msg := <-channel.msgs:
+		t.Fatalf("expected no alert for an unreachable endpoint, got %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if status := m.Status(); status.State != monitor.StateFailing {
+		t.Fatalf("expected failing status, got %+v", status)
+	}
+}
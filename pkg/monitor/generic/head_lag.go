@@ -0,0 +1,238 @@
+package generic
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/alert"
+	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
+	"github.com/numbergroup/eth-monitor/pkg/monitor"
+)
+
+// RPCHead returns the head of an endpoint, the block number for execution endpoints
+// or the slot for consensus endpoints.
+type RPCHead interface {
+	Head(ctx context.Context) (uint64, error)
+}
+
+type headLagMember struct {
+	endpoint    config.Endpoint
+	client      RPCHead
+	alerts      *alert.Lifecycle
+	log         logrus.Ext1FieldLogger
+	head        uint64
+	headErr     error
+	behindSince time.Time
+}
+
+// HeadLagMonitor compares the heads of all endpoints of the same type in a network
+// against the best head of the network's reference endpoints, alerting on endpoints
+// that stay behind by more than their configured lag for longer than a grace period.
+type HeadLagMonitor struct {
+	conf     *config.Config
+	network  string
+	typeName string
+	members  []*headLagMember
+	log      logrus.Ext1FieldLogger
+	tracker  *monitor.Tracker
+}
+
+func NewHeadLagMonitor(conf *config.Config, network string, typeName string) *HeadLagMonitor {
+	out := &HeadLagMonitor{
+		conf:     conf,
+		network:  network,
+		typeName: typeName,
+	}
+	out.log = conf.Log.WithFields(logrus.Fields{
+		"name":    out.Name(),
+		"network": network,
+	})
+	out.tracker = monitor.NewTracker(conf, out.Name(), "")
+	return out
+}
+
+// AddEndpoint adds an endpoint of the network to the comparison. It must be called before Run.
+func (m *HeadLagMonitor) AddEndpoint(endpoint config.Endpoint, client RPCHead, alertChannels []alert.Alert) {
+	log := m.log.WithField("endpoint", endpoint.Name)
+	m.members = append(m.members, &headLagMember{
+		endpoint: endpoint,
+		client:   client,
+		alerts:   alert.NewLifecycle(log, alertChannels, endpoint.Name, m.Name()),
+		log:      log,
+	})
+}
+
+func (m *HeadLagMonitor) Name() string {
+	return m.typeName + "::HeadLagMonitor::" + m.network
+}
+
+// pollDuration is the shortest poll duration of the network's endpoints.
+func (m *HeadLagMonitor) pollDuration() time.Duration {
+	var out time.Duration
+	for _, member := range m.members {
+		if out == 0 || (member.endpoint.PollDuration > 0 && member.endpoint.PollDuration < out) {
+			out = member.endpoint.PollDuration
+		}
+	}
+	return out
+}
+
+func (m *HeadLagMonitor) fetchHeads(ctx context.Context) {
+	wg := sync.WaitGroup{}
+	for _, member := range m.members {
+		wg.Add(1)
+		go func(member *headLagMember) {
+			defer wg.Done()
+			rpcCtx, cancel := context.WithTimeout(ctx, m.conf.RPCTimeout)
+			defer cancel()
+
+			start := time.Now()
+			head, err := member.client.Head(rpcCtx)
+			metrics.ObserveRPC(member.endpoint.Name, "head", start, err)
+			member.headErr = err
+			if err == nil {
+				member.head = head
+			}
+		}(member)
+	}
+	wg.Wait()
+}
+
+// referenceHead returns the best head among the reference endpoints that responded,
+// or among all endpoints if none is marked as reference.
+func (m *HeadLagMonitor) referenceHead() (uint64, bool) {
+	hasReference := false
+	for _, member := range m.members {
+		if member.endpoint.Reference {
+			hasReference = true
+			break
+		}
+	}
+
+	var (
+		best  uint64
+		found bool
+	)
+	for _, member := range m.members {
+		if member.headErr != nil || (hasReference && !member.endpoint.Reference) {
+			continue
+		}
+		if !found || member.head > best {
+			best = member.head
+			found = true
+		}
+	}
+	return best, found
+}
+
+// checkMember returns an error if the member has been behind the reference head by more
+// than its configured lag for longer than its grace period.
+func (m *HeadLagMonitor) checkMember(member *headLagMember, reference uint64) error {
+	var lag uint64
+	if reference > member.head {
+		lag = reference - member.head
+	}
+	metrics.HeadLag.WithLabelValues(member.endpoint.Name, m.typeName).Set(float64(lag))
+
+	if member.endpoint.HeadLag.MaxLag == 0 || lag <= member.endpoint.HeadLag.MaxLag {
+		member.behindSince = time.Time{}
+		return nil
+	}
+
+	if member.behindSince.IsZero() {
+		member.behindSince = time.Now()
+	}
+	behindFor := time.Since(member.behindSince)
+	if behindFor <= member.endpoint.HeadLag.GracePeriod {
+		return nil
+	}
+	return errors.Errorf("head %d is %d behind the %s reference head %d for %s, expected at most %d",
+		member.head, lag, m.network, reference, behindFor.Round(time.Second), member.endpoint.HeadLag.MaxLag)
+}
+
+func (m *HeadLagMonitor) check(ctx context.Context) {
+	m.fetchHeads(ctx)
+
+	heads := map[string]any{}
+	for _, member := range m.members {
+		if member.headErr == nil {
+			heads[member.endpoint.Name] = member.head
+		}
+	}
+	reference, ok := m.referenceHead()
+	if !ok {
+		m.tracker.Record(map[string]any{"heads": heads}, errors.New("no reference head available"))
+		m.log.Warn("no reference head available, skipping head lag comparison")
+		return
+	}
+
+	var lagging []string
+	for _, member := range m.members {
+		if member.headErr != nil {
+			// Unreachable endpoints are reported by their own monitors
+			member.log.WithError(member.headErr).Warn("failed to get head, skipping head lag comparison")
+			continue
+		}
+
+		if err := m.checkMember(member, reference); err != nil {
+			lagging = append(lagging, member.endpoint.Name)
+			member.log.WithError(err).Error("health check failed, raising alert")
+			alertErr := member.alerts.Trigger(ctx, alert.Message{
+				Message:  err.Error(),
+				Severity: member.endpoint.Severity.HeadLag.OrDefault(),
+				Metadata: map[string]any{
+					"network":        m.network,
+					"head":           member.head,
+					"reference_head": reference,
+				},
+			})
+			if alertErr != nil {
+				member.log.WithError(alertErr).Error("failed to raise alert")
+			}
+		} else {
+			member.log.WithFields(logrus.Fields{
+				"head":           member.head,
+				"reference_head": reference,
+			}).Debug("Endpoint is in sync with reference")
+			if alertErr := member.alerts.Resolve(ctx); alertErr != nil {
+				member.log.WithError(alertErr).Error("failed to resolve alert")
+			}
+		}
+	}
+
+	var err error
+	if len(lagging) > 0 {
+		err = errors.Errorf("endpoints behind reference head: %s", strings.Join(lagging, ", "))
+	}
+	m.tracker.Record(map[string]any{"heads": heads, "reference_head": reference}, err)
+}
+
+func (m *HeadLagMonitor) Status() monitor.Status {
+	return m.tracker.Status()
+}
+
+func (m *HeadLagMonitor) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		default:
+			m.check(ctx)
+		}
+
+		select {
+		case <-time.After(m.pollDuration()):
+			continue
+		case <-ctx.Done():
+			m.log.Info("monitoring stopped")
+			return
+		}
+	}
+}
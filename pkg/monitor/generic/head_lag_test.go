@@ -0,0 +1,112 @@
+package generic
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+type fakeHeadRPC struct {
+	ret uint64
+	err error
+}
+
+func (f *fakeHeadRPC) Head(ctx context.Context) (uint64, error) { return f.ret, f.err }
+
+func newHeadLagTestMonitor(t *testing.T) *HeadLagMonitor {
+	t.Helper()
+	conf := &config.Config{Log: logrus.New(), RPCTimeout: time.Second}
+	return NewHeadLagMonitor(conf, "mainnet", config.TypeExecution)
+}
+
+func TestHeadLag_ReferenceHead_PrefersReferences(t *testing.T) {
+	m := newHeadLagTestMonitor(t)
+	m.AddEndpoint(config.Endpoint{Name: "ref", Reference: true}, &fakeHeadRPC{ret: 100}, nil)
+	m.AddEndpoint(config.Endpoint{Name: "ahead"}, &fakeHeadRPC{ret: 120}, nil)
+
+	m.fetchHeads(t.Context())
+	got, ok := m.referenceHead()
+	if !ok || got != 100 {
+		t.Fatalf("expected reference head 100, got %d (ok=%v)", got, ok)
+	}
+}
+
+func TestHeadLag_ReferenceHead_BestOfGroupWithoutReferences(t *testing.T) {
+	m := newHeadLagTestMonitor(t)
+	m.AddEndpoint(config.Endpoint{Name: "a"}, &fakeHeadRPC{ret: 100}, nil)
+	m.AddEndpoint(config.Endpoint{Name: "b"}, &fakeHeadRPC{ret: 120}, nil)
+	m.AddEndpoint(config.Endpoint{Name: "down"}, &fakeHeadRPC{err: assertErr{}}, nil)
+
+	m.fetchHeads(t.Context())
+	got, ok := m.referenceHead()
+	if !ok || got != 120 {
+		t.Fatalf("expected best head 120, got %d (ok=%v)", got, ok)
+	}
+}
+
+func TestHeadLag_ReferenceHead_NoneAvailable(t *testing.T) {
+	m := newHeadLagTestMonitor(t)
+	m.AddEndpoint(config.Endpoint{Name: "ref", Reference: true}, &fakeHeadRPC{err: assertErr{}}, nil)
+	m.AddEndpoint(config.Endpoint{Name: "a"}, &fakeHeadRPC{ret: 100}, nil)
+
+	m.fetchHeads(t.Context())
+	if _, ok := m.referenceHead(); ok {
+		t.Fatal("expected no reference head when all references are unreachable")
+	}
+}
+
+func TestHeadLag_WithinMaxLag_OK(t *testing.T) {
+	m := newHeadLagTestMonitor(t)
+	m.AddEndpoint(config.Endpoint{Name: "a", HeadLag: config.HeadLag{MaxLag: 5}}, &fakeHeadRPC{ret: 97}, nil)
+	member := m.members[0]
+	member.head = 97
+
+	if err := m.checkMember(member, 100); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !member.behindSince.IsZero() {
+		t.Fatal("expected behindSince to be unset")
+	}
+}
+
+func TestHeadLag_BehindWithinGrace_OK(t *testing.T) {
+	m := newHeadLagTestMonitor(t)
+	m.AddEndpoint(config.Endpoint{Name: "a", HeadLag: config.HeadLag{MaxLag: 5, GracePeriod: time.Minute}}, &fakeHeadRPC{}, nil)
+	member := m.members[0]
+	member.head = 90
+
+	if err := m.checkMember(member, 100); err != nil {
+		t.Fatalf("expected no error within grace period, got %v", err)
+	}
+	if member.behindSince.IsZero() {
+		t.Fatal("expected behindSince to be set")
+	}
+}
+
+func TestHeadLag_BehindPastGrace_Error(t *testing.T) {
+	m := newHeadLagTestMonitor(t)
+	m.AddEndpoint(config.Endpoint{Name: "a", HeadLag: config.HeadLag{MaxLag: 5, GracePeriod: time.Second}}, &fakeHeadRPC{}, nil)
+	member := m.members[0]
+	member.head = 90
+	member.behindSince = time.Now().Add(-time.Minute)
+
+	err := m.checkMember(member, 100)
+	if err == nil {
+		t.Fatal("expected error when behind past grace period, got nil")
+	}
+	if !strings.Contains(err.Error(), "is 10 behind") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+}
+
+func TestHeadLag_Name(t *testing.T) {
+	m := newHeadLagTestMonitor(t)
+	if got, want := m.Name(), "execution::HeadLagMonitor::mainnet"; got != want {
+		t.Fatalf("unexpected name got %q want %q", got, want)
+	}
+}
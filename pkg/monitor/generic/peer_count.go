@@ -9,6 +9,7 @@ import (
 
 	"github.com/numbergroup/eth-monitor/pkg/alert"
 	"github.com/numbergroup/eth-monitor/pkg/config"
+	"github.com/numbergroup/eth-monitor/pkg/metrics"
 	"github.com/numbergroup/eth-monitor/pkg/monitor"
 )
 
@@ -17,55 +18,141 @@ type RPCPeerCount interface {
 	PeerCount(ctx context.Context) (uint64, error)
 }
 
+// peerThreshold raises an alert of the given severity while the peer count is below it.
+type peerThreshold struct {
+	below    int
+	severity alert.Severity
+}
+
 type PeerCountMonitor struct {
-	alertChannels       []alert.Alert
+	alerts              *alert.Lifecycle
 	conf                *config.Config
 	client              RPCPeerCount
 	endpoint            config.Endpoint
+	thresholds          []peerThreshold
+	highest             int // Highest threshold, which the startup and recovery levels are derived from
+	recoverAbove        int
+	margin              int // Peers the count must recover above the threshold alerted on before the alert is downgraded to a less severe threshold
+	samples             int
 	lastPeerCount       uint64
-	hasEverBeenAboveMin bool // Tracks if peer count has ever been above minimum, to avoid false alerts on startup
+	hasEverBeenAboveMin bool          // Tracks if peer count has ever been above minimum, to avoid false alerts on startup
+	badSamples          int           // Consecutive samples below a threshold
+	alerting            bool          // An alert is raised until the peer count recovers above recoverAbove
+	tier                peerThreshold // Threshold the raised alert is for
+	severity            alert.Severity
 	log                 logrus.Ext1FieldLogger
 	typeName            string
+	tracker             *monitor.Tracker
 }
 
 func NewPeerCountMonitor(conf *config.Config, alertChannels []alert.Alert, rpcClient RPCPeerCount, endpoint config.Endpoint, typeName string) (monitor.Monitor, error) {
 	out := &PeerCountMonitor{
-		alertChannels:       alertChannels,
 		conf:                conf,
 		client:              rpcClient,
 		endpoint:            endpoint,
 		hasEverBeenAboveMin: false,
 		typeName:            typeName,
+		samples:             max(endpoint.PeerCount.Samples, 1),
+		severity:            endpoint.Severity.PeerCount.OrDefault(),
+	}
+	if endpoint.MinPeers > 0 {
+		out.thresholds = append(out.thresholds, peerThreshold{below: endpoint.MinPeers, severity: endpoint.Severity.PeerCount.OrDefault()})
+	}
+	if endpoint.PeerCount.Warning > 0 {
+		out.thresholds = append(out.thresholds, peerThreshold{below: endpoint.PeerCount.Warning, severity: alert.Warning})
+	}
+	if endpoint.PeerCount.Critical > 0 {
+		out.thresholds = append(out.thresholds, peerThreshold{below: endpoint.PeerCount.Critical, severity: alert.Critical})
+	}
+	for _, threshold := range out.thresholds {
+		out.highest = max(out.highest, threshold.below)
 	}
+	out.recoverAbove = endpoint.PeerCount.RecoverAbove
+	if out.recoverAbove == 0 {
+		out.recoverAbove = out.highest - 1
+	}
+	out.margin = max(out.recoverAbove-out.highest+1, 1)
 	out.log = conf.Log.WithFields(logrus.Fields{
 		"name":     out.Name(),
 		"endpoint": endpoint.Name,
 	})
+	out.alerts = alert.NewLifecycle(out.log, alertChannels, endpoint.Name, out.Name())
+	out.tracker = monitor.NewTracker(conf, out.Name(), endpoint.Name)
 
 	return out, nil
 }
 
 func (m *PeerCountMonitor) checkPeerCount(ctx context.Context) error {
+	start := time.Now()
 	pc, err := m.client.PeerCount(ctx)
+	metrics.ObserveRPC(m.endpoint.Name, "peer_count", start, err)
 	if err != nil {
 		return errors.Wrap(err, "failed to get peer count")
 	}
 	m.lastPeerCount = pc
+	metrics.PeerCount.WithLabelValues(m.endpoint.Name, m.typeName).Set(float64(pc))
 
-	if m.endpoint.MinPeers > 0 && int(pc) < m.endpoint.MinPeers && m.hasEverBeenAboveMin {
-		return errors.Errorf("peer count %d below minimum %d", pc, m.endpoint.MinPeers)
+	threshold, below := m.breachedThreshold(pc)
+	if !m.hasEverBeenAboveMin {
+		if int(pc) > m.highest+config.PeerStartThreshold {
+			m.hasEverBeenAboveMin = true
+		}
+		return nil
 	}
-	if int(pc) > m.endpoint.MinPeers+config.PeerStartThreshold {
-		m.hasEverBeenAboveMin = true
+
+	if !below {
+		if m.alerting && int(pc) <= m.recoverAbove {
+			return errors.Errorf("peer count %d has not recovered above %d", pc, m.recoverAbove)
+		}
+		m.badSamples = 0
+		m.alerting = false
+		return nil
 	}
 
-	return nil
+	m.badSamples++
+	if m.badSamples < m.samples && !m.alerting {
+		m.log.WithFields(logrus.Fields{
+			"peers":   pc,
+			"samples": m.badSamples,
+		}).Warn("peer count below threshold, waiting for consecutive samples before alerting")
+		return nil
+	}
+	// A count swinging around a more severe threshold would otherwise change the severity, and re-raise the alert, on every sample
+	if m.alerting && !threshold.severity.AtLeast(m.tier.severity) && int(pc) <= m.tier.below+m.margin {
+		threshold = m.tier
+	}
+	m.alerting = true
+	m.tier = threshold
+	m.severity = threshold.severity
+	return errors.Errorf("peer count %d below minimum %d", pc, threshold.below)
+}
+
+// breachedThreshold returns the most severe threshold the peer count is below.
+func (m *PeerCountMonitor) breachedThreshold(pc uint64) (peerThreshold, bool) {
+	var (
+		out   peerThreshold
+		found bool
+	)
+	for _, threshold := range m.thresholds {
+		if int(pc) >= threshold.below {
+			continue
+		}
+		if !found || !out.severity.AtLeast(threshold.severity) {
+			out = threshold
+			found = true
+		}
+	}
+	return out, found
 }
 
 func (m *PeerCountMonitor) Name() string {
 	return m.typeName + "::PeerCountMonitor::" + m.endpoint.Name
 }
 
+func (m *PeerCountMonitor) Status() monitor.Status {
+	return m.tracker.Status()
+}
+
 func (m *PeerCountMonitor) Run(ctx context.Context) {
 	for {
 		select {
@@ -73,12 +160,13 @@ func (m *PeerCountMonitor) Run(ctx context.Context) {
 			m.conf.Log.WithField("name", m.endpoint.Name).Info("monitoring stopped")
 			return
 		default:
-			if err := m.checkPeerCount(ctx); err != nil {
+			err := m.checkPeerCount(ctx)
+			m.tracker.Record(map[string]any{"peers": m.lastPeerCount}, err)
+			if err != nil {
 				m.log.WithError(err).Error("health check failed, raising alert")
-				alertErr := alert.RaiseAll(ctx, m.log, m.alertChannels, alert.Message{
+				alertErr := m.alerts.Trigger(ctx, alert.Message{
 					Message:  err.Error(),
-					Severity: alert.Error,
-					Name:     m.endpoint.Name,
+					Severity: m.severity,
 				})
 				if alertErr != nil {
 					m.log.WithError(alertErr).Error("failed to raise alert")
@@ -88,6 +176,9 @@ func (m *PeerCountMonitor) Run(ctx context.Context) {
 					"peers": m.lastPeerCount,
 					"name":  m.endpoint.Name,
 				}).Info("Endpoint is healthy")
+				if alertErr := m.alerts.Resolve(ctx); alertErr != nil {
+					m.log.WithError(alertErr).Error("failed to resolve alert")
+				}
 			}
 		}
 
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/numbergroup/eth-monitor/pkg/alert"
 	"github.com/numbergroup/eth-monitor/pkg/config"
 	"github.com/sirupsen/logrus"
 )
@@ -133,3 +134,113 @@ func TestPeerCount_Name(t *testing.T) {
 		t.Fatalf("unexpected name got %q want %q", got, want)
 	}
 }
+
+func TestPeerCount_TieredThresholds(t *testing.T) {
+	rpc := &fakePeerRPC{ret: 20}
+	ep := config.Endpoint{PeerCount: config.PeerCount{Warning: 10, Critical: 4}}
+	m := newPeerTestMonitor(t, rpc, ep)
+
+	if err := m.checkPeerCount(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rpc.ret = 8
+	if err := m.checkPeerCount(t.Context()); err == nil {
+		t.Fatal("expected error below warning threshold")
+	}
+	if m.severity != alert.Warning {
+		t.Fatalf("expected warning severity, got %q", m.severity)
+	}
+
+	rpc.ret = 3
+	if err := m.checkPeerCount(t.Context()); err == nil {
+		t.Fatal("expected error below critical threshold")
+	}
+	if m.severity != alert.Critical {
+		t.Fatalf("expected critical severity, got %q", m.severity)
+	}
+}
+
+func TestPeerCount_SeverityHysteresis(t *testing.T) {
+	rpc := &fakePeerRPC{ret: 20}
+	ep := config.Endpoint{PeerCount: config.PeerCount{Warning: 10, Critical: 3}}
+	m := newPeerTestMonitor(t, rpc, ep)
+	_ = m.checkPeerCount(t.Context())
+
+	// Swinging around the critical threshold keeps the alert critical
+	for _, peers := range []uint64{2, 4, 2, 4} {
+		rpc.ret = peers
+		if err := m.checkPeerCount(t.Context()); err == nil {
+			t.Fatalf("expected error at %d peers", peers)
+		}
+		if m.severity != alert.Critical {
+			t.Fatalf("expected critical severity at %d peers, got %q", peers, m.severity)
+		}
+	}
+
+	// Recovered above the critical threshold plus the margin, the alert is downgraded
+	rpc.ret = 5
+	if err := m.checkPeerCount(t.Context()); err == nil {
+		t.Fatal("expected error below warning threshold")
+	}
+	if m.severity != alert.Warning {
+		t.Fatalf("expected warning severity, got %q", m.severity)
+	}
+}
+
+func TestPeerCount_Hysteresis(t *testing.T) {
+	rpc := &fakePeerRPC{ret: 20}
+	ep := config.Endpoint{PeerCount: config.PeerCount{Warning: 10, RecoverAbove: 12}}
+	m := newPeerTestMonitor(t, rpc, ep)
+	_ = m.checkPeerCount(t.Context())
+
+	rpc.ret = 9
+	if err := m.checkPeerCount(t.Context()); err == nil {
+		t.Fatal("expected error below warning threshold")
+	}
+
+	// Back above the threshold but not above recover_above, the alert stays raised
+	rpc.ret = 11
+	err := m.checkPeerCount(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "has not recovered above 12") {
+		t.Fatalf("expected alert to stay raised, got %v", err)
+	}
+
+	rpc.ret = 13
+	if err := m.checkPeerCount(t.Context()); err != nil {
+		t.Fatalf("expected recovery above recover_above, got %v", err)
+	}
+
+	// Without an active alert, the band between the threshold and recover_above is healthy
+	rpc.ret = 11
+	if err := m.checkPeerCount(t.Context()); err != nil {
+		t.Fatalf("expected no error above threshold, got %v", err)
+	}
+}
+
+func TestPeerCount_ConsecutiveSamples(t *testing.T) {
+	rpc := &fakePeerRPC{ret: 20}
+	ep := config.Endpoint{MinPeers: 5, PeerCount: config.PeerCount{Samples: 3}}
+	m := newPeerTestMonitor(t, rpc, ep)
+	_ = m.checkPeerCount(t.Context())
+
+	rpc.ret = 2
+	for i := 0; i < 2; i++ {
+		if err := m.checkPeerCount(t.Context()); err != nil {
+			t.Fatalf("expected no alert on sample %d, got %v", i+1, err)
+		}
+	}
+
+	// A good sample resets the count
+	rpc.ret = 6
+	_ = m.checkPeerCount(t.Context())
+	rpc.ret = 2
+	for i := 0; i < 2; i++ {
+		if err := m.checkPeerCount(t.Context()); err != nil {
+			t.Fatalf("expected no alert after reset on sample %d, got %v", i+1, err)
+		}
+	}
+	if err := m.checkPeerCount(t.Context()); err == nil {
+		t.Fatal("expected alert on third consecutive bad sample")
+	}
+}
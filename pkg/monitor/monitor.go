@@ -1,8 +1,115 @@
 package monitor
 
-import "context"
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
 
 type Monitor interface {
 	Run(ctx context.Context)
 	Name() string
+	Status() Status
+}
+
+type State string
+
+const (
+	StateUnknown State = "unknown" // No check has completed yet
+	StateHealthy State = "healthy"
+	StateFailing State = "failing"
+)
+
+// Status is a snapshot of the outcome of a monitor's last check.
+type Status struct {
+	Name      string         `json:"name"`
+	Endpoint  string         `json:"endpoint,omitempty"`
+	State     State          `json:"state"`
+	Started   time.Time      `json:"started"`
+	LastCheck time.Time      `json:"last_check"`
+	Value     map[string]any `json:"value,omitempty"`
+	LastError string         `json:"last_error,omitempty"`
+	Silences  []string       `json:"silences,omitempty"` // Silences suppressing the alerts of the endpoint, set by the status API
+}
+
+// Tracker records the outcome of a monitor's checks, so that it can be read through
+// Status from another goroutine while the monitor runs.
+type Tracker struct {
+	conf   *config.Config
+	mu     sync.RWMutex
+	status Status
+}
+
+func NewTracker(conf *config.Config, name string, endpoint string) *Tracker {
+	return &Tracker{
+		conf: conf,
+		status: Status{
+			Name:     name,
+			Endpoint: endpoint,
+			State:    StateUnknown,
+			Started:  time.Now(),
+		},
+	}
+}
+
+// Record stores the outcome of a check, value holds the observations made during the check.
+func (t *Tracker) Record(value map[string]any, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastCheck = time.Now()
+	t.status.Value = value
+	if err != nil {
+		t.status.State = StateFailing
+		t.status.LastError = t.conf.Redact(err.Error())
+	} else {
+		t.status.State = StateHealthy
+		t.status.LastError = ""
+	}
+}
+
+func (t *Tracker) Status() Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.status
+}
+
+// Registry holds the running monitors so that their status can be reported.
+type Registry struct {
+	mu       sync.RWMutex
+	monitors map[string]Monitor
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		monitors: map[string]Monitor{},
+	}
+}
+
+func (r *Registry) Register(m Monitor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.monitors[m.Name()] = m
+}
+
+func (r *Registry) Unregister(m Monitor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.monitors, m.Name())
+}
+
+// Statuses returns the status of all registered monitors, sorted by name.
+func (r *Registry) Statuses() []Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Status, 0, len(r.monitors))
+	for _, m := range r.monitors {
+		out = append(out, m.Status())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
 }
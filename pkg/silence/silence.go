@@ -0,0 +1,173 @@
+// Package silence keeps the silences suppressing alerts during maintenance, both those from the
+// configuration and those added at runtime through the admin API.
+package silence
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+var (
+	ErrNotFound   = errors.New("silence not found")
+	ErrExists     = errors.New("silence already exists")
+	ErrConfigured = errors.New("silence is defined in the configuration")
+)
+
+// Entry is a silence, where it was defined and whether it currently suppresses alerts.
+type Entry struct {
+	config.Silence
+	Source string `json:"source"`
+	Active bool   `json:"active"`
+}
+
+// Manager holds the silences of the configuration, replaced on every reload, and the silences added
+// at runtime, which are kept in memory until they end or are deleted.
+type Manager struct {
+	mu         sync.Mutex
+	endpoints  map[string]config.Endpoint // Keyed by endpoint name
+	configured []config.Silence
+	runtime    []config.Silence
+	next       int
+	now        func() time.Time
+}
+
+func NewManager(conf *config.Config) *Manager {
+	m := &Manager{now: time.Now}
+	m.Update(conf)
+	return m
+}
+
+// Update replaces the configured silences and the endpoints they are matched against with those of conf.
+func (m *Manager) Update(conf *config.Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.configured = slices.Clone(conf.Silences)
+	m.endpoints = make(map[string]config.Endpoint, len(conf.Endpoints))
+	for _, endpoint := range conf.Endpoints {
+		m.endpoints[endpoint.Name] = endpoint
+	}
+}
+
+// Add adds a runtime silence, starting immediately if it has no start and named after a counter if it has no name.
+func (m *Manager) Add(silence config.Silence) (config.Silence, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if silence.Recurring.Enabled() {
+		return config.Silence{}, errors.New("recurring silences can only be defined in the configuration")
+	}
+	if silence.Start.IsZero() {
+		silence.Start = now
+	}
+	if silence.Name == "" {
+		for silence.Name == "" || m.lookup(silence.Name) != "" {
+			m.next++
+			silence.Name = fmt.Sprintf("silence-%d", m.next)
+		}
+	}
+	if err := silence.Validate(); err != nil {
+		return config.Silence{}, err
+	}
+	if silence.Expired(now) {
+		return config.Silence{}, errors.New("end must be in the future")
+	}
+	if m.lookup(silence.Name) != "" {
+		return config.Silence{}, errors.Wrapf(ErrExists, "silence %q", silence.Name)
+	}
+	m.runtime = append(m.runtime, silence)
+	return silence, nil
+}
+
+// Delete removes the runtime silence named name. Configured silences can only be removed from the configuration.
+func (m *Manager) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch m.lookup(name) {
+	case SourceAPI:
+		m.runtime = slices.DeleteFunc(m.runtime, func(s config.Silence) bool {
+			return s.Name == name
+		})
+		return nil
+	case SourceConfig:
+		return errors.Wrapf(ErrConfigured, "silence %q", name)
+	default:
+		return errors.Wrapf(ErrNotFound, "silence %q", name)
+	}
+}
+
+// lookup returns the source of the silence named name, or an empty string if there is none.
+func (m *Manager) lookup(name string) string {
+	m.prune()
+	if slices.ContainsFunc(m.runtime, func(s config.Silence) bool { return s.Name == name }) {
+		return SourceAPI
+	}
+	if slices.ContainsFunc(m.configured, func(s config.Silence) bool { return s.Name == name }) {
+		return SourceConfig
+	}
+	return ""
+}
+
+// prune drops the runtime silences that have ended.
+func (m *Manager) prune() {
+	now := m.now()
+	m.runtime = slices.DeleteFunc(m.runtime, func(s config.Silence) bool {
+		return s.Expired(now)
+	})
+}
+
+// List returns every silence, configured ones first.
+func (m *Manager) List() []Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+	now := m.now()
+	out := make([]Entry, 0, len(m.configured)+len(m.runtime))
+	for _, silence := range m.configured {
+		out = append(out, Entry{Silence: silence, Source: SourceConfig, Active: silence.ActiveAt(now)})
+	}
+	for _, silence := range m.runtime {
+		out = append(out, Entry{Silence: silence, Source: SourceAPI, Active: silence.ActiveAt(now)})
+	}
+	return out
+}
+
+// Active returns the names of the silences currently suppressing the alerts of endpoint.
+func (m *Manager) Active(endpoint config.Endpoint) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+	now := m.now()
+	var out []string
+	for _, silence := range slices.Concat(m.configured, m.runtime) {
+		if silence.ActiveAt(now) && silence.Match.Matches(endpoint) {
+			out = append(out, silence.Name)
+		}
+	}
+	return out
+}
+
+// ActiveFor returns the names of the silences currently suppressing the alerts of the endpoint named name.
+func (m *Manager) ActiveFor(name string) []string {
+	m.mu.Lock()
+	endpoint, ok := m.endpoints[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return m.Active(endpoint)
+}
@@ -0,0 +1,113 @@
+package silence
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/numbergroup/eth-monitor/pkg/config"
+)
+
+func newTestManager(now *time.Time) *Manager {
+	m := NewManager(&config.Config{
+		Endpoints: []config.Endpoint{
+			{Name: "mainnet-el", Labels: map[string]string{"env": "prod"}},
+			{Name: "holesky-el", Labels: map[string]string{"env": "test"}},
+		},
+		Silences: []config.Silence{{
+			Name:  "maintenance",
+			Match: config.EndpointMatch{Labels: map[string]string{"env": "test"}},
+			Recurring: config.Recurring{
+				At:       "02:00",
+				Duration: time.Hour,
+			},
+		}},
+	})
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func TestManagerActive(t *testing.T) {
+	now := time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC)
+	m := newTestManager(&now)
+
+	if got := m.ActiveFor("holesky-el"); !slices.Equal(got, []string{"maintenance"}) {
+		t.Fatalf("expected the recurring silence to be active, got %v", got)
+	}
+	if got := m.ActiveFor("mainnet-el"); len(got) != 0 {
+		t.Fatalf("expected no silence of mainnet-el, got %v", got)
+	}
+
+	added, err := m.Add(config.Silence{
+		Match: config.EndpointMatch{Endpoints: []string{"*-el"}},
+		End:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added.Name != "silence-1" || !added.Start.Equal(now) {
+		t.Fatalf("expected a named silence starting now, got %+v", added)
+	}
+	if got := m.ActiveFor("holesky-el"); !slices.Equal(got, []string{"maintenance", "silence-1"}) {
+		t.Fatalf("unexpected silences got %v", got)
+	}
+
+	now = now.Add(time.Hour)
+	if got := m.ActiveFor("holesky-el"); len(got) != 0 {
+		t.Fatalf("expected every silence to have ended, got %v", got)
+	}
+	if entries := m.List(); len(entries) != 1 || entries[0].Source != SourceConfig || entries[0].Active {
+		t.Fatalf("expected the ended runtime silence to be pruned, got %+v", entries)
+	}
+	if got := m.ActiveFor("unknown"); got != nil {
+		t.Fatalf("expected no silences of an unknown endpoint, got %v", got)
+	}
+}
+
+func TestManagerAddAndDelete(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newTestManager(&now)
+	match := config.EndpointMatch{Endpoints: []string{"mainnet-el"}}
+
+	if _, err := m.Add(config.Silence{Name: "maintenance", Match: match, End: now.Add(time.Hour)}); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, err := m.Add(config.Silence{Match: match, Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}); err == nil {
+		t.Fatal("expected an error for a silence that already ended")
+	}
+	if _, err := m.Add(config.Silence{Match: match}); err == nil {
+		t.Fatal("expected an error for a silence without end")
+	}
+	if _, err := m.Add(config.Silence{Match: match, Recurring: config.Recurring{At: "02:00", Duration: time.Hour}}); err == nil {
+		t.Fatal("expected an error for a recurring runtime silence")
+	}
+	if _, err := m.Add(config.Silence{Name: "upgrade", Match: match, End: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.Delete("maintenance"); !errors.Is(err, ErrConfigured) {
+		t.Fatalf("expected ErrConfigured, got %v", err)
+	}
+	if err := m.Delete("upgrade"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Delete("upgrade"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestManagerUpdateKeepsRuntimeSilences(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := newTestManager(&now)
+	if _, err := m.Add(config.Silence{Name: "upgrade", Match: config.EndpointMatch{Endpoints: []string{"mainnet-el"}}, End: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m.Update(&config.Config{Endpoints: []config.Endpoint{{Name: "mainnet-el"}}})
+	entries := m.List()
+	if len(entries) != 1 || entries[0].Name != "upgrade" || !entries[0].Active {
+		t.Fatalf("expected only the runtime silence after reload, got %+v", entries)
+	}
+}